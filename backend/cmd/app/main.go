@@ -1,7 +1,113 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // for the Pacific Time location used by the quota meter
+
+	"github.com/caarlos0/env/v11"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository/adapter"
+	"github.com/tocoteron/omigoto/backend/module/youtube/usecase"
+	"github.com/tocoteron/omigoto/backend/omikun"
+)
+
+type config struct {
+	Addr                    string        `env:"ADDR" envDefault:":8080"`
+	YouTubeAPIKey           string        `env:"YOUTUBE_API_KEY,notEmpty"`
+	YouTubeChannelHandle    string        `env:"YOUTUBE_CHANNEL_HANDLE"` // omikun's channel if empty
+	YouTubeQuotaDailyBudget int64         `env:"YOUTUBE_QUOTA_DAILY_BUDGET" envDefault:"10000"`
+	WebSubCallbackURL       string        `env:"WEBSUB_CALLBACK_URL,notEmpty"` // public URL routed to /websub/youtube
+	WebSubSecret            string        `env:"WEBSUB_SECRET,notEmpty"`
+	WebSubLease             time.Duration `env:"WEBSUB_LEASE" envDefault:"120h"` // the hub may grant a shorter one
+	DatabaseURL             string        `env:"DATABASE_URL,notEmpty"`
+}
+
+const (
+	// maxNotificationSize is the largest notification body accepted from the hub.
+	maxNotificationSize = 1 << 20
+	// httpTimeout bounds each request to the hub and the channel feed.
+	httpTimeout = 30 * time.Second
+)
 
 func main() {
-	fmt.Println("omigoto backend is running")
+	var cfg config
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("failed to parse config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	youtubeDBRepo := adapter.NewYouTubeDBRepository(pool)
+
+	quotaMeter, err := adapter.NewYouTubeQuotaMeter(adapter.NewYouTubeQuotaStore(pool), cfg.YouTubeQuotaDailyBudget)
+	if err != nil {
+		log.Fatalf("failed to create youtube quota meter: %v", err)
+	}
+
+	youtubeRepo, err := adapter.NewYouTubeRepository(ctx, cfg.YouTubeAPIKey, adapter.WithQuotaMeter(quotaMeter))
+	if err != nil {
+		log.Fatalf("failed to create youtube repository: %v", err)
+	}
+
+	handle := omikun.YouTubeChannel.Handle
+	if cfg.YouTubeChannelHandle != "" {
+		handle = model.YouTubeChannelHandle(cfg.YouTubeChannelHandle)
+	}
+
+	channelID, err := usecase.NewYouTubeSyncUsecase(youtubeRepo, youtubeDBRepo).ResolveChannelID(ctx, handle)
+	if err != nil {
+		log.Fatalf("failed to resolve channel: %v", err)
+	}
+
+	httpClient := &http.Client{Timeout: httpTimeout}
+	feedUsecase := usecase.NewYouTubeFeedUsecase(adapter.NewYouTubeFeedRepository(httpClient), youtubeRepo, youtubeDBRepo)
+	hub := adapter.NewYouTubeWebSubHub(httpClient, adapter.YouTubeWebSubHubURL, cfg.WebSubSecret)
+	webSubUsecase := usecase.NewYouTubeWebSubUsecase(hub, feedUsecase, channelID, cfg.WebSubCallbackURL, cfg.WebSubLease)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /websub/youtube", verifyWebSubIntent(webSubUsecase))
+	mux.HandleFunc("POST /websub/youtube", receiveWebSubNotification(webSubUsecase))
+
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("omigoto backend is listening on %s", cfg.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	// The hub verifies the subscription by calling back, so the server has to be up first.
+	go func() {
+		if err := webSubUsecase.MaintainSubscription(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("stopped maintaining websub subscription: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"github.com/tocoteron/omigoto/backend/module/youtube/usecase"
+)
+
+// verifyWebSubIntent answers the hub verifying a subscription, echoing its challenge
+// only for the subscriptions the app asked for.
+func verifyWebSubIntent(webSubUsecase *usecase.YouTubeWebSubUsecase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		// A missing or malformed lease is passed as 0, which stands for the lease asked for
+		var lease time.Duration
+		if leaseSeconds, err := strconv.ParseInt(query.Get("hub.lease_seconds"), 10, 64); err == nil && leaseSeconds > 0 {
+			lease = time.Duration(leaseSeconds) * time.Second
+		}
+
+		if !webSubUsecase.VerifyIntent(query.Get("hub.mode"), query.Get("hub.topic"), lease) {
+			log.Printf("websub: refused %s of %s", query.Get("hub.mode"), query.Get("hub.topic"))
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, query.Get("hub.challenge"))
+	}
+}
+
+// receiveWebSubNotification saves the videos pushed by the hub. Failures other than a bad
+// signature are answered with an error status, so that the hub delivers the notification again.
+func receiveWebSubNotification(webSubUsecase *usecase.YouTubeWebSubUsecase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxNotificationSize))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		counts, err := webSubUsecase.HandleNotification(r.Context(), body, r.Header.Get("X-Hub-Signature"))
+		if errors.Is(err, repository.ErrInvalidSignature) {
+			// WebSub asks subscribers to acknowledge notifications with a bad signature
+			// all the same, and to just ignore them.
+			log.Printf("websub: ignored notification: %v", err)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if err != nil {
+			log.Printf("websub: failed to handle notification: %v", err)
+			http.Error(w, "failed to handle notification", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("websub: videos: %s", counts)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
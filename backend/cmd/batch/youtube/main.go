@@ -1,7 +1,255 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	_ "time/tzdata" // for the Pacific Time location used by the quota meter
+
+	"github.com/caarlos0/env/v11"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository/adapter"
+	"github.com/tocoteron/omigoto/backend/module/youtube/usecase"
+	"github.com/tocoteron/omigoto/backend/omikun"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+	"google.golang.org/api/youtube/v3"
+)
+
+type config struct {
+	YouTubeAPIKey           string `env:"YOUTUBE_API_KEY,notEmpty"`
+	YouTubeChannelHandle    string `env:"YOUTUBE_CHANNEL_HANDLE"` // omikun's channel if empty
+	YouTubeQuotaDailyBudget int64  `env:"YOUTUBE_QUOTA_DAILY_BUDGET" envDefault:"10000"`
+	YouTubeResponseCache    string `env:"YOUTUBE_RESPONSE_CACHE" envDefault:"db"` // db, memory or none
+	YouTubeSyncMode         string `env:"YOUTUBE_SYNC_MODE" envDefault:"full"`    // full, or feed to only pick up new videos from the channel feed
+	YouTubeSyncComments     bool   `env:"YOUTUBE_SYNC_COMMENTS" envDefault:"false"`
+	YouTubeSyncCaptions     bool   `env:"YOUTUBE_SYNC_CAPTIONS" envDefault:"false"`
+	DatabaseURL             string `env:"DATABASE_URL,notEmpty"`
+
+	// OAuth credentials of the channel owner, needed to download captions
+	YouTubeOAuthClientID     string `env:"YOUTUBE_OAUTH_CLIENT_ID"`
+	YouTubeOAuthClientSecret string `env:"YOUTUBE_OAUTH_CLIENT_SECRET"`
+	YouTubeOAuthRefreshToken string `env:"YOUTUBE_OAUTH_REFRESH_TOKEN"`
+}
+
+// httpTimeout bounds each request to endpoints other than the Data API, such as the channel feed.
+const httpTimeout = 30 * time.Second
 
 func main() {
-	fmt.Println("YouTube batch is running")
+	var cfg config
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("failed to parse config: %v", err)
+	}
+
+	if cfg.YouTubeSyncMode != "full" && cfg.YouTubeSyncMode != "feed" {
+		log.Fatalf("unknown youtube sync mode: %s", cfg.YouTubeSyncMode)
+	}
+
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	youtubeDBRepo := adapter.NewYouTubeDBRepository(pool)
+
+	quotaMeter, err := adapter.NewYouTubeQuotaMeter(adapter.NewYouTubeQuotaStore(pool), cfg.YouTubeQuotaDailyBudget)
+	if err != nil {
+		log.Fatalf("failed to create youtube quota meter: %v", err)
+	}
+
+	youtubeRepoOpts := []adapter.YouTubeRepositoryOption{
+		adapter.WithQuotaMeter(quotaMeter),
+	}
+	switch cfg.YouTubeResponseCache {
+	case "db":
+		youtubeRepoOpts = append(youtubeRepoOpts, adapter.WithResponseCache(adapter.NewYouTubeDBResponseCache(adapter.NewYouTubeAPIResponseCacheStore(pool))))
+	case "memory":
+		youtubeRepoOpts = append(youtubeRepoOpts, adapter.WithResponseCache(adapter.NewYouTubeMemoryResponseCache()))
+	case "none":
+	default:
+		log.Fatalf("unknown youtube response cache: %s", cfg.YouTubeResponseCache)
+	}
+
+	if cfg.YouTubeOAuthRefreshToken != "" {
+		oauthConfig := &oauth2.Config{
+			ClientID:     cfg.YouTubeOAuthClientID,
+			ClientSecret: cfg.YouTubeOAuthClientSecret,
+			Endpoint:     endpoints.Google,
+			Scopes:       []string{youtube.YoutubeForceSslScope},
+		}
+		tokenSource := oauthConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: cfg.YouTubeOAuthRefreshToken})
+		youtubeRepoOpts = append(youtubeRepoOpts, adapter.WithTokenSource(tokenSource))
+	}
+
+	youtubeRepo, err := adapter.NewYouTubeRepository(ctx, cfg.YouTubeAPIKey, youtubeRepoOpts...)
+	if err != nil {
+		log.Fatalf("failed to create youtube repository: %v", err)
+	}
+
+	handle := omikun.YouTubeChannel.Handle
+	if cfg.YouTubeChannelHandle != "" {
+		handle = model.YouTubeChannelHandle(cfg.YouTubeChannelHandle)
+	}
+
+	syncUsecase := usecase.NewYouTubeSyncUsecase(youtubeRepo, youtubeDBRepo)
+
+	channelID, err := syncUsecase.ResolveChannelID(ctx, handle)
+	if errors.Is(err, repository.ErrQuotaBudgetExceeded) {
+		log.Printf("stopped resolving YouTube channel %s: daily quota budget of %d units is used up", handle, cfg.YouTubeQuotaDailyBudget)
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to resolve channel: %v", err)
+	}
+
+	if cfg.YouTubeSyncMode == "feed" {
+		feedUsecase := usecase.NewYouTubeFeedUsecase(adapter.NewYouTubeFeedRepository(&http.Client{Timeout: httpTimeout}), youtubeRepo, youtubeDBRepo)
+		syncFeed(ctx, feedUsecase, channelID)
+		return
+	}
+
+	log.Printf("syncing YouTube channel %s (%s)", handle, channelID)
+
+	stats, err := syncUsecase.SyncChannel(ctx, channelID)
+	if errors.Is(err, repository.ErrQuotaBudgetExceeded) {
+		// Nothing has been saved because the sync stops before writing to the database.
+		// The next run after the quota is reset picks it up again.
+		log.Printf("stopped syncing YouTube channel %s: daily quota budget of %d units is used up", channelID, cfg.YouTubeQuotaDailyBudget)
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to sync channel: %v", err)
+	}
+
+	log.Printf("synced YouTube channel %s", channelID)
+	log.Printf("channels: %s", stats.Channels)
+	log.Printf("playlists: %s", stats.Playlists)
+	log.Printf("videos: %s", stats.Videos)
+	log.Printf("playlist videos: %s removed=%d", stats.PlaylistVideos, stats.PlaylistVideosRemoved)
+	if len(stats.ChannelProfileChanges) > 0 {
+		log.Printf("channel profile changed: %v", stats.ChannelProfileChanges)
+	}
+	for _, m := range stats.ViewMilestones {
+		log.Printf("video %s passed %d views", m.VideoID, m.Milestone)
+	}
+	for _, c := range stats.AvailabilityChanges {
+		log.Printf("video %s is now %s", c.VideoID, c.Availability)
+	}
+
+	if cfg.YouTubeSyncComments {
+		syncComments(ctx, syncUsecase, youtubeDBRepo, channelID)
+	}
+	if cfg.YouTubeSyncCaptions {
+		syncCaptions(ctx, syncUsecase, youtubeDBRepo, channelID)
+	}
+
+	used, err := quotaMeter.Usage(ctx)
+	if err != nil {
+		log.Fatalf("failed to get quota usage: %v", err)
+	}
+	log.Printf("quota used today: %d/%d units", used, cfg.YouTubeQuotaDailyBudget)
+}
+
+// syncFeed saves the videos in the channel feed that are not in the database yet.
+// It spends no quota unless the feed has a new video.
+func syncFeed(ctx context.Context, feedUsecase *usecase.YouTubeFeedUsecase, channelID model.YouTubeChannelID) {
+	videoIDs, counts, err := feedUsecase.SyncNewVideos(ctx, channelID)
+	if errors.Is(err, repository.ErrQuotaBudgetExceeded) {
+		log.Printf("stopped syncing new videos of YouTube channel %s: daily quota budget is used up", channelID)
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to sync new videos: %v", err)
+	}
+
+	if len(videoIDs) == 0 {
+		log.Printf("no new videos in the feed of YouTube channel %s", channelID)
+		return
+	}
+	log.Printf("new videos in the feed of YouTube channel %s: %v", channelID, videoIDs)
+	log.Printf("videos: %s", counts)
+}
+
+// syncComments archives the comments of every video of the channel.
+// Running out of quota part way is fine; videos already done are saved
+// and the rest are picked up by the next run.
+func syncComments(
+	ctx context.Context,
+	syncUsecase *usecase.YouTubeSyncUsecase,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	channelID model.YouTubeChannelID,
+) {
+	videoIDs, err := youtubeDBRepo.ListVideoIDsByChannel(ctx, channelID)
+	if err != nil {
+		log.Fatalf("failed to list video IDs by channel: %v", err)
+	}
+
+	var total usecase.UpsertCounts
+	for _, videoID := range videoIDs {
+		counts, err := syncUsecase.SyncVideoComments(ctx, videoID)
+		if errors.Is(err, repository.ErrQuotaBudgetExceeded) {
+			log.Printf("stopped syncing comments at video %s: daily quota budget is used up", videoID)
+			break
+		}
+		if err != nil {
+			log.Fatalf("failed to sync comments of video %s: %v", videoID, err)
+		}
+
+		total.Inserted += counts.Inserted
+		total.Updated += counts.Updated
+		total.Unchanged += counts.Unchanged
+	}
+
+	log.Printf("comments: %s", total)
+}
+
+// syncCaptions archives the captions of every video of the channel.
+// Like syncComments, it stops without failing when the quota runs out.
+func syncCaptions(
+	ctx context.Context,
+	syncUsecase *usecase.YouTubeSyncUsecase,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	channelID model.YouTubeChannelID,
+) {
+	videoIDs, err := youtubeDBRepo.ListVideoIDsByChannel(ctx, channelID)
+	if err != nil {
+		log.Fatalf("failed to list video IDs by channel: %v", err)
+	}
+
+	var tracks usecase.UpsertCounts
+	var downloaded, skipped int
+	for _, videoID := range videoIDs {
+		stats, err := syncUsecase.SyncVideoCaptions(ctx, videoID)
+		if stats != nil {
+			tracks.Inserted += stats.Tracks.Inserted
+			tracks.Updated += stats.Tracks.Updated
+			tracks.Unchanged += stats.Tracks.Unchanged
+			downloaded += stats.Downloaded
+			skipped += stats.Skipped
+		}
+		if errors.Is(err, repository.ErrQuotaBudgetExceeded) {
+			log.Printf("stopped syncing captions at video %s: daily quota budget is used up", videoID)
+			break
+		}
+		if errors.Is(err, repository.ErrUnauthorized) {
+			log.Printf("stopped syncing captions at video %s: YOUTUBE_OAUTH_* are missing or not valid: %v", videoID, err)
+			break
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			// The video is gone
+			continue
+		}
+		if err != nil {
+			log.Fatalf("failed to sync captions of video %s: %v", videoID, err)
+		}
+	}
+
+	log.Printf("caption tracks: %s downloaded=%d skipped=%d", tracks, downloaded, skipped)
 }
@@ -35,7 +35,7 @@ func main() {
 	}
 	fmt.Printf("channel: %+v\n", channel)
 
-	playlists, err := listAllPlaylists(ctx, youtubeRepo, omikun.YouTubeChannel.ID)
+	playlists, err := repository.Collect(repository.AllPlaylists(ctx, youtubeRepo, omikun.YouTubeChannel.ID))
 	if err != nil {
 		log.Fatalf("failed to list playlists: %v", err)
 	}
@@ -47,17 +47,18 @@ func main() {
 	}
 	fmt.Printf("uploadsPlaylist: %+v\n", uploadsPlaylist)
 
-	videoIDs, err := listAllVideoIDs(ctx, youtubeRepo, uploadsPlaylist.ID)
+	videoIDs, err := repository.Collect(repository.AllVideoIDsByPlaylist(ctx, youtubeRepo, uploadsPlaylist.ID))
 	if err != nil {
 		log.Fatalf("failed to list video IDs: %v", err)
 	}
 	fmt.Printf("videoIDs: %+v\n", videoIDs)
 
-	videos, err := listAllVideos(ctx, youtubeRepo, videoIDs[:50])
+	videos, missingVideoIDs, err := repository.ListVideosInBatches(ctx, youtubeRepo, videoIDs, 4)
 	if err != nil {
 		log.Fatalf("failed to list videos: %v", err)
 	}
 	fmt.Printf("videos: %+v\n", videos)
+	fmt.Printf("missingVideoIDs: %+v\n", missingVideoIDs)
 }
 
 func getChannel(
@@ -85,81 +86,3 @@ func getPlaylist(
 
 	return playlist, nil
 }
-
-func listAllPlaylists(
-	ctx context.Context,
-	youtubeRepo repository.YouTubeRepository,
-	channelID model.YouTubeChannelID,
-) ([]*model.YouTubePlaylist, error) {
-	playlists := make([]*model.YouTubePlaylist, 0)
-
-	var pageToken *repository.YouTubePageToken
-	for {
-		pls, _, nextPageToken, err := youtubeRepo.ListPlaylists(ctx, channelID, pageToken)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list playlists: %w", err)
-		}
-
-		playlists = append(playlists, pls...)
-
-		if nextPageToken == nil {
-			break
-		}
-
-		pageToken = nextPageToken
-	}
-
-	return playlists, nil
-}
-
-func listAllVideoIDs(
-	ctx context.Context,
-	youtubeRepo repository.YouTubeRepository,
-	playlistID model.YouTubePlaylistID,
-) ([]model.YouTubeVideoID, error) {
-	videoIDs := make([]model.YouTubeVideoID, 0)
-
-	var pageToken *repository.YouTubePageToken
-	for {
-		ids, _, nextPageToken, err := youtubeRepo.ListVideoIDsByPlaylist(ctx, playlistID, pageToken)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list video IDs: %w", err)
-		}
-
-		videoIDs = append(videoIDs, ids...)
-
-		if nextPageToken == nil {
-			break
-		}
-
-		pageToken = nextPageToken
-	}
-
-	return videoIDs, nil
-}
-
-func listAllVideos(
-	ctx context.Context,
-	youtubeRepo repository.YouTubeRepository,
-	videoIDs []model.YouTubeVideoID,
-) ([]*model.YouTubeVideo, error) {
-	videos := make([]*model.YouTubeVideo, 0)
-
-	var pageToken *repository.YouTubePageToken
-	for {
-		vs, _, nextPageToken, err := youtubeRepo.ListVideos(ctx, videoIDs, pageToken)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list videos: %w", err)
-		}
-
-		videos = append(videos, vs...)
-
-		if nextPageToken == nil {
-			break
-		}
-
-		pageToken = nextPageToken
-	}
-
-	return videos, nil
-}
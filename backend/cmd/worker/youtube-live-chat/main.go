@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // for the Pacific Time location used by the quota meter
+
+	"github.com/caarlos0/env/v11"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository/adapter"
+	"github.com/tocoteron/omigoto/backend/module/youtube/usecase"
+)
+
+type config struct {
+	YouTubeAPIKey           string        `env:"YOUTUBE_API_KEY,notEmpty"`
+	YouTubeQuotaDailyBudget int64         `env:"YOUTUBE_QUOTA_DAILY_BUDGET" envDefault:"10000"`
+	CheckInterval           time.Duration `env:"YOUTUBE_LIVE_CHAT_CHECK_INTERVAL" envDefault:"5m"`        // how often to look for live broadcasts
+	LeadTime                time.Duration `env:"YOUTUBE_LIVE_CHAT_LEAD_TIME" envDefault:"15m"`            // how early to start on upcoming broadcasts
+	MinPollingInterval      time.Duration `env:"YOUTUBE_LIVE_CHAT_MIN_POLLING_INTERVAL" envDefault:"10s"` // each poll costs 5 units
+	DatabaseURL             string        `env:"DATABASE_URL,notEmpty"`
+}
+
+// The worker looks for live broadcasts in the database, which the sync batch keeps up to date,
+// and records the live chat of each of them until the chat ends.
+func main() {
+	var cfg config
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("failed to parse config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	youtubeDBRepo := adapter.NewYouTubeDBRepository(pool)
+
+	quotaMeter, err := adapter.NewYouTubeQuotaMeter(adapter.NewYouTubeQuotaStore(pool), cfg.YouTubeQuotaDailyBudget)
+	if err != nil {
+		log.Fatalf("failed to create youtube quota meter: %v", err)
+	}
+
+	youtubeRepo, err := adapter.NewYouTubeRepository(ctx, cfg.YouTubeAPIKey, adapter.WithQuotaMeter(quotaMeter))
+	if err != nil {
+		log.Fatalf("failed to create youtube repository: %v", err)
+	}
+
+	liveChatUsecase := usecase.NewYouTubeLiveChatUsecase(youtubeRepo, youtubeDBRepo, cfg.MinPollingInterval)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	capturing := make(map[model.YouTubeVideoID]struct{})
+
+	ticker := time.NewTicker(cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		videoIDs, err := liveChatUsecase.ListLiveVideoIDs(ctx, time.Now(), cfg.LeadTime)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("failed to list live videos: %v", err)
+		}
+
+		for _, videoID := range videoIDs {
+			mu.Lock()
+			_, ok := capturing[videoID]
+			if !ok {
+				capturing[videoID] = struct{}{}
+			}
+			mu.Unlock()
+			if ok {
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() {
+					mu.Lock()
+					delete(capturing, videoID)
+					mu.Unlock()
+				}()
+
+				captureLiveChat(ctx, liveChatUsecase, videoID)
+			}()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Printf("stopping; waiting for live chat captures to finish")
+			wg.Wait()
+			return
+		}
+	}
+}
+
+func captureLiveChat(ctx context.Context, liveChatUsecase *usecase.YouTubeLiveChatUsecase, videoID model.YouTubeVideoID) {
+	log.Printf("capturing live chat of video %s", videoID)
+
+	saved, err := liveChatUsecase.CaptureLiveChat(ctx, videoID)
+	switch {
+	case errors.Is(err, usecase.ErrNoActiveLiveChat):
+		// Upcoming broadcasts are checked again on the next round
+		log.Printf("video %s has no live chat open", videoID)
+	case errors.Is(err, repository.ErrQuotaBudgetExceeded):
+		log.Printf("stopped capturing live chat of video %s after %d messages: daily quota budget is used up", videoID, saved)
+	case errors.Is(err, context.Canceled):
+		log.Printf("stopped capturing live chat of video %s after %d messages", videoID, saved)
+	case err != nil:
+		log.Printf("failed to capture live chat of video %s after %d messages: %v", videoID, saved, err)
+	default:
+		log.Printf("live chat of video %s ended; saved %d messages", videoID, saved)
+	}
+}
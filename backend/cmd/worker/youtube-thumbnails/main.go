@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository/adapter"
+	"github.com/tocoteron/omigoto/backend/module/youtube/usecase"
+)
+
+type config struct {
+	CheckInterval     time.Duration `env:"YOUTUBE_THUMBNAIL_CHECK_INTERVAL" envDefault:"10m"`    // how often to look for new thumbnails
+	RecheckInterval   time.Duration `env:"YOUTUBE_THUMBNAIL_RECHECK_INTERVAL" envDefault:"168h"` // how often to download archived thumbnails again to find replaced images
+	BlobStore         string        `env:"BLOB_STORE" envDefault:"local"`                        // local or s3
+	BlobLocalDir      string        `env:"BLOB_LOCAL_DIR" envDefault:"./blobs"`
+	S3Endpoint        string        `env:"S3_ENDPOINT"`
+	S3Region          string        `env:"S3_REGION" envDefault:"auto"`
+	S3Bucket          string        `env:"S3_BUCKET"`
+	S3AccessKeyID     string        `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string        `env:"S3_SECRET_ACCESS_KEY"`
+	S3PathStyle       bool          `env:"S3_PATH_STYLE"`
+	DatabaseURL       string        `env:"DATABASE_URL,notEmpty"`
+}
+
+// httpTimeout bounds each request for an image or to the blob store.
+const httpTimeout = 1 * time.Minute
+
+// The worker looks for thumbnails in the database, which the sync batch keeps up to date,
+// and archives the image at each new thumbnail URL to the blob store. Archived thumbnails are
+// downloaded again every RecheckInterval, as YouTube can replace an image at the same URL.
+func main() {
+	var cfg config
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("failed to parse config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	httpClient := &http.Client{Timeout: httpTimeout}
+
+	blobStore, err := newBlobStore(cfg, httpClient)
+	if err != nil {
+		log.Fatalf("failed to create blob store: %v", err)
+	}
+
+	thumbnailUsecase := usecase.NewYouTubeThumbnailArchiveUsecase(
+		adapter.NewYouTubeImageRepository(httpClient),
+		adapter.NewYouTubeDBRepository(pool),
+		blobStore,
+		cfg.RecheckInterval,
+	)
+
+	ticker := time.NewTicker(cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		archiveThumbnails(ctx, thumbnailUsecase)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func newBlobStore(cfg config, httpClient *http.Client) (repository.BlobStore, error) {
+	switch cfg.BlobStore {
+	case "local":
+		return adapter.NewLocalBlobStore(cfg.BlobLocalDir), nil
+	case "s3":
+		return adapter.NewS3BlobStore(httpClient, adapter.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q: must be local or s3", cfg.BlobStore)
+	}
+}
+
+func archiveThumbnails(ctx context.Context, thumbnailUsecase *usecase.YouTubeThumbnailArchiveUsecase) {
+	videoIDs, err := thumbnailUsecase.ListVideoIDsToArchive(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("failed to list videos to archive: %v", err)
+		}
+		return
+	}
+
+	total := 0
+	for _, videoID := range videoIDs {
+		archived, err := thumbnailUsecase.ArchiveVideoThumbnails(ctx, videoID)
+		total += archived
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			// Keep going; the video is tried again on the next round
+			log.Printf("failed to archive thumbnails of video %s: %v", videoID, err)
+		}
+	}
+
+	log.Printf("archived %d new thumbnail images of %d videos", total, len(videoIDs))
+}
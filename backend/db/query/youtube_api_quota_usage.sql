@@ -0,0 +1,12 @@
+-- name: ReserveYouTubeAPIQuota :one
+-- Adds units to the usage of the day unless the total would exceed the budget, in which case no row is returned.
+INSERT INTO youtube_api_quota_usage (usage_date, units)
+VALUES (@usage_date::date, @units::bigint)
+ON CONFLICT (usage_date) DO UPDATE
+SET units = youtube_api_quota_usage.units + EXCLUDED.units
+WHERE youtube_api_quota_usage.units + EXCLUDED.units <= @budget::bigint
+RETURNING units;
+
+-- name: GetYouTubeAPIQuotaUsage :one
+SELECT units FROM youtube_api_quota_usage
+WHERE usage_date = $1;
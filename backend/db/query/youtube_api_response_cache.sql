@@ -0,0 +1,11 @@
+-- name: GetYouTubeAPIResponseCache :one
+SELECT * FROM youtube_api_response_cache
+WHERE cache_key = $1;
+
+-- name: UpsertYouTubeAPIResponseCache :exec
+INSERT INTO youtube_api_response_cache (cache_key, etag, body, updated_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (cache_key) DO UPDATE
+SET etag = EXCLUDED.etag,
+    body = EXCLUDED.body,
+    updated_at = EXCLUDED.updated_at;
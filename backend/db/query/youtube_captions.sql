@@ -0,0 +1,45 @@
+-- name: UpsertYouTubeCaptionTrack :one
+INSERT INTO youtube_caption_tracks (caption_id, video_id, language, name, track_kind, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (caption_id) DO UPDATE
+SET language = EXCLUDED.language,
+    name = EXCLUDED.name,
+    track_kind = EXCLUDED.track_kind,
+    updated_at = EXCLUDED.updated_at
+WHERE (
+    youtube_caption_tracks.language, youtube_caption_tracks.name,
+    youtube_caption_tracks.track_kind, youtube_caption_tracks.updated_at
+) IS DISTINCT FROM (
+    EXCLUDED.language, EXCLUDED.name,
+    EXCLUDED.track_kind, EXCLUDED.updated_at
+)
+RETURNING (xmax = 0) AS inserted;
+
+-- name: ListYouTubeCaptionTracksByVideo :many
+SELECT * FROM youtube_caption_tracks
+WHERE video_id = $1
+ORDER BY language, track_kind, caption_id;
+
+-- name: ListStaleYouTubeCaptionTracksByVideo :many
+-- Lists the tracks whose segments have not been saved since the track was last updated.
+SELECT * FROM youtube_caption_tracks
+WHERE video_id = $1 AND segments_updated_at IS DISTINCT FROM updated_at
+ORDER BY language, track_kind, caption_id;
+
+-- name: DeleteYouTubeCaptionSegments :exec
+DELETE FROM youtube_caption_segments
+WHERE caption_id = $1;
+
+-- name: CreateYouTubeCaptionSegment :exec
+INSERT INTO youtube_caption_segments (caption_id, seq, start_offset, end_offset, text)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: SetYouTubeCaptionTrackSegmentsUpdatedAt :exec
+UPDATE youtube_caption_tracks
+SET segments_updated_at = $2
+WHERE caption_id = $1;
+
+-- name: ListYouTubeCaptionSegments :many
+SELECT * FROM youtube_caption_segments
+WHERE caption_id = $1
+ORDER BY seq;
@@ -0,0 +1,17 @@
+-- name: CreateYouTubeChannelSnapshot :exec
+INSERT INTO youtube_channel_snapshots (channel_id, captured_at, title, description, avatar_url, banner_url, subscriber_count, view_count, video_count)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetLatestYouTubeChannelSnapshot :one
+SELECT * FROM youtube_channel_snapshots
+WHERE channel_id = $1
+ORDER BY captured_at DESC
+LIMIT 1;
+
+-- name: ListYouTubeChannelSnapshots :many
+-- Lists the snapshots captured in [since, until), oldest first.
+SELECT * FROM youtube_channel_snapshots
+WHERE channel_id = @channel_id::text
+    AND captured_at >= @since::timestamptz
+    AND captured_at < @until::timestamptz
+ORDER BY captured_at;
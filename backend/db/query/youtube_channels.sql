@@ -1,6 +1,6 @@
 -- name: CreateYouTubeChannel :exec
-INSERT INTO youtube_channels (channel_id, handle, uploads_playlist_id)
-VALUES ($1, $2, $3);
+INSERT INTO youtube_channels (channel_id, handle, uploads_playlist_id, title, description, country, avatar_url, banner_url, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: GetYouTubeChannel :one
 SELECT * FROM youtube_channels
@@ -9,3 +9,19 @@ WHERE channel_id = $1;
 -- name: GetYouTubeChannelByHandle :one
 SELECT * FROM youtube_channels
 WHERE handle = $1;
+
+-- name: UpsertYouTubeChannel :one
+INSERT INTO youtube_channels (channel_id, handle, uploads_playlist_id, title, description, country, avatar_url, banner_url, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (channel_id) DO UPDATE
+SET handle = EXCLUDED.handle,
+    uploads_playlist_id = EXCLUDED.uploads_playlist_id,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    country = EXCLUDED.country,
+    avatar_url = EXCLUDED.avatar_url,
+    banner_url = EXCLUDED.banner_url,
+    published_at = EXCLUDED.published_at
+WHERE (youtube_channels.handle, youtube_channels.uploads_playlist_id, youtube_channels.title, youtube_channels.description, youtube_channels.country, youtube_channels.avatar_url, youtube_channels.banner_url, youtube_channels.published_at)
+    IS DISTINCT FROM (EXCLUDED.handle, EXCLUDED.uploads_playlist_id, EXCLUDED.title, EXCLUDED.description, EXCLUDED.country, EXCLUDED.avatar_url, EXCLUDED.banner_url, EXCLUDED.published_at)
+RETURNING (xmax = 0) AS inserted;
@@ -0,0 +1,37 @@
+-- name: UpsertYouTubeComment :one
+INSERT INTO youtube_comments (
+    comment_id, video_id, parent_id, author_display_name, author_channel_id,
+    text, like_count, reply_count, published_at, updated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (comment_id) DO UPDATE
+SET author_display_name = EXCLUDED.author_display_name,
+    author_channel_id = EXCLUDED.author_channel_id,
+    text = EXCLUDED.text,
+    like_count = EXCLUDED.like_count,
+    reply_count = EXCLUDED.reply_count,
+    updated_at = EXCLUDED.updated_at
+WHERE (
+    youtube_comments.author_display_name, youtube_comments.author_channel_id,
+    youtube_comments.text, youtube_comments.like_count, youtube_comments.reply_count, youtube_comments.updated_at
+) IS DISTINCT FROM (
+    EXCLUDED.author_display_name, EXCLUDED.author_channel_id,
+    EXCLUDED.text, EXCLUDED.like_count, EXCLUDED.reply_count, EXCLUDED.updated_at
+)
+RETURNING (xmax = 0) AS inserted;
+
+-- name: ListYouTubeCommentsByVideo :many
+SELECT * FROM youtube_comments
+WHERE video_id = $1
+ORDER BY published_at, comment_id;
+
+-- name: GetYouTubeCommentSync :one
+SELECT * FROM youtube_comment_syncs
+WHERE video_id = $1;
+
+-- name: UpsertYouTubeCommentSync :exec
+INSERT INTO youtube_comment_syncs (video_id, synced_at, refreshed_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (video_id) DO UPDATE
+SET synced_at = EXCLUDED.synced_at,
+    refreshed_at = EXCLUDED.refreshed_at;
@@ -0,0 +1,16 @@
+-- name: CreateYouTubeLiveChatMessage :execrows
+-- Messages already saved are skipped, since polling may return them again.
+INSERT INTO youtube_live_chat_messages (
+    message_id, video_id, message_type,
+    author_channel_id, author_display_name, author_is_owner, author_is_moderator, author_is_member,
+    text, published_at,
+    super_chat_amount_micros, super_chat_currency, super_chat_amount_display, super_chat_tier,
+    member_level_name, member_months, gift_count
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: ListYouTubeLiveChatMessages :many
+SELECT * FROM youtube_live_chat_messages
+WHERE video_id = $1
+ORDER BY published_at, message_id;
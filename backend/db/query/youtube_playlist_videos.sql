@@ -1,7 +1,58 @@
 -- name: CreateYouTubePlaylistVideo :exec
-INSERT INTO youtube_playlist_videos (playlist_id, video_id)
-VALUES ($1, $2);
+INSERT INTO youtube_playlist_videos (playlist_id, video_id, added_at, position, video_published_at)
+VALUES ($1, $2, $3, $4, $5);
 
 -- name: ListYouTubePlaylistVideoIDs :many
-SELECT video_id FROM youtube_playlist_videos
-WHERE playlist_id = $1;
+-- Lists the videos currently in the playlist in playlist order, only of the given class unless it is NULL.
+SELECT pv.video_id FROM youtube_playlist_videos pv
+JOIN youtube_videos v ON v.video_id = pv.video_id
+WHERE pv.playlist_id = @playlist_id::text
+    AND pv.removed_at IS NULL
+    AND (sqlc.narg('class')::text IS NULL OR v.class = sqlc.narg('class')::text)
+ORDER BY pv.position;
+
+-- name: ListYouTubePlaylistVideos :many
+-- Lists every membership of the playlist, including past ones, oldest first.
+SELECT * FROM youtube_playlist_videos
+WHERE playlist_id = $1
+ORDER BY added_at, position;
+
+-- name: FillYouTubePlaylistVideoAddedAt :exec
+-- Memberships saved before added_at was recorded have it at the epoch.
+-- The first sync that lists the video fills it in, instead of opening a second membership.
+UPDATE youtube_playlist_videos pv
+SET added_at = @added_at::timestamptz
+WHERE pv.playlist_id = @playlist_id::text
+    AND pv.video_id = @video_id::text
+    AND pv.added_at = 'epoch'
+    AND pv.removed_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM youtube_playlist_videos other
+        WHERE other.playlist_id = pv.playlist_id AND other.video_id = pv.video_id AND other.added_at = @added_at::timestamptz
+    );
+
+-- name: UpsertYouTubePlaylistVideo :one
+-- A membership that was marked removed is reopened if the video turns out to still be in the playlist.
+INSERT INTO youtube_playlist_videos (playlist_id, video_id, added_at, position, video_published_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (playlist_id, video_id, added_at) DO UPDATE
+SET position = EXCLUDED.position,
+    video_published_at = EXCLUDED.video_published_at,
+    removed_at = NULL
+WHERE (youtube_playlist_videos.position, youtube_playlist_videos.video_published_at, youtube_playlist_videos.removed_at)
+    IS DISTINCT FROM (EXCLUDED.position, EXCLUDED.video_published_at, NULL::timestamptz)
+RETURNING (xmax = 0) AS inserted;
+
+-- name: RemoveYouTubePlaylistVideos :execrows
+-- Marks the current memberships that are no longer listed in the playlist as removed.
+-- A membership is listed if a video is listed with the same added_at, so a video that was
+-- removed and added again between two syncs has its old membership closed.
+-- Memberships whose added_at was never recorded are matched by the video only.
+UPDATE youtube_playlist_videos pv
+SET removed_at = @removed_at::timestamptz
+WHERE pv.playlist_id = @playlist_id::text
+    AND pv.removed_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM unnest(@listed_video_ids::text[], @listed_added_ats::timestamptz[]) AS listed (video_id, added_at)
+        WHERE listed.video_id = pv.video_id AND (listed.added_at = pv.added_at OR pv.added_at = 'epoch')
+    );
@@ -1,6 +1,10 @@
 -- name: CreateYouTubePlaylist :exec
-INSERT INTO youtube_playlists (playlist_id, channel_id, title)
-VALUES ($1, $2, $3);
+INSERT INTO youtube_playlists (
+    playlist_id, channel_id, title, description,
+    thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
+    item_count, privacy_status, published_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
 
 -- name: GetYouTubePlaylist :one
 SELECT * FROM youtube_playlists
@@ -13,3 +17,33 @@ WHERE playlist_id = ANY(@playlist_ids::text[]);
 -- name: ListPlaylistIDsByChannel :many
 SELECT playlist_id FROM youtube_playlists
 WHERE channel_id = $1;
+
+-- name: UpsertYouTubePlaylist :one
+INSERT INTO youtube_playlists (
+    playlist_id, channel_id, title, description,
+    thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
+    item_count, privacy_status, published_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (playlist_id) DO UPDATE
+SET channel_id = EXCLUDED.channel_id,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    thumbnail_default_url = EXCLUDED.thumbnail_default_url,
+    thumbnail_medium_url = EXCLUDED.thumbnail_medium_url,
+    thumbnail_high_url = EXCLUDED.thumbnail_high_url,
+    thumbnail_standard_url = EXCLUDED.thumbnail_standard_url,
+    thumbnail_maxres_url = EXCLUDED.thumbnail_maxres_url,
+    item_count = EXCLUDED.item_count,
+    privacy_status = EXCLUDED.privacy_status,
+    published_at = EXCLUDED.published_at
+WHERE (
+    youtube_playlists.channel_id, youtube_playlists.title, youtube_playlists.description,
+    youtube_playlists.thumbnail_default_url, youtube_playlists.thumbnail_medium_url, youtube_playlists.thumbnail_high_url, youtube_playlists.thumbnail_standard_url, youtube_playlists.thumbnail_maxres_url,
+    youtube_playlists.item_count, youtube_playlists.privacy_status, youtube_playlists.published_at
+) IS DISTINCT FROM (
+    EXCLUDED.channel_id, EXCLUDED.title, EXCLUDED.description,
+    EXCLUDED.thumbnail_default_url, EXCLUDED.thumbnail_medium_url, EXCLUDED.thumbnail_high_url, EXCLUDED.thumbnail_standard_url, EXCLUDED.thumbnail_maxres_url,
+    EXCLUDED.item_count, EXCLUDED.privacy_status, EXCLUDED.published_at
+)
+RETURNING (xmax = 0) AS inserted;
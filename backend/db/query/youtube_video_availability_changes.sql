@@ -0,0 +1,8 @@
+-- name: CreateYouTubeVideoAvailabilityChange :exec
+INSERT INTO youtube_video_availability_changes (video_id, observed_at, availability)
+VALUES ($1, $2, $3);
+
+-- name: ListYouTubeVideoAvailabilityChanges :many
+SELECT * FROM youtube_video_availability_changes
+WHERE video_id = $1
+ORDER BY observed_at;
@@ -0,0 +1,27 @@
+-- name: CreateYouTubeVideoStatistics :exec
+INSERT INTO youtube_video_statistics (video_id, captured_at, view_count, like_count, comment_count)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetLatestYouTubeVideoStatistics :one
+SELECT * FROM youtube_video_statistics
+WHERE video_id = $1
+ORDER BY captured_at DESC
+LIMIT 1;
+
+-- name: ListYouTubeVideoStatistics :many
+-- Lists the statistics captured in [since, until), oldest first.
+SELECT * FROM youtube_video_statistics
+WHERE video_id = @video_id::text
+    AND captured_at >= @since::timestamptz
+    AND captured_at < @until::timestamptz
+ORDER BY captured_at;
+
+-- name: ListFastestGrowingYouTubeVideos :many
+-- Ranks videos by the views they gained between the first and last statistics captured in [since, until).
+SELECT video_id, (max(view_count) - min(view_count))::bigint AS view_gain
+FROM youtube_video_statistics
+WHERE captured_at >= @since::timestamptz
+    AND captured_at < @until::timestamptz
+GROUP BY video_id
+ORDER BY view_gain DESC, video_id
+LIMIT @max_results::int;
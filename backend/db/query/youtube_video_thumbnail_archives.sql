@@ -0,0 +1,65 @@
+-- name: SetYouTubeVideoThumbnailSHA256 :exec
+-- Records the hash of the archived image of the thumbnail of the size,
+-- unless the URL of the thumbnail has changed since the image was downloaded.
+UPDATE youtube_videos
+SET thumbnail_default_sha256 = CASE WHEN @size::text = 'default' THEN @sha256::text ELSE thumbnail_default_sha256 END,
+    thumbnail_medium_sha256 = CASE WHEN @size::text = 'medium' THEN @sha256::text ELSE thumbnail_medium_sha256 END,
+    thumbnail_high_sha256 = CASE WHEN @size::text = 'high' THEN @sha256::text ELSE thumbnail_high_sha256 END,
+    thumbnail_standard_sha256 = CASE WHEN @size::text = 'standard' THEN @sha256::text ELSE thumbnail_standard_sha256 END,
+    thumbnail_maxres_sha256 = CASE WHEN @size::text = 'maxres' THEN @sha256::text ELSE thumbnail_maxres_sha256 END
+WHERE video_id = @video_id::text
+  AND CASE @size::text
+      WHEN 'default' THEN thumbnail_default_url
+      WHEN 'medium' THEN thumbnail_medium_url
+      WHEN 'high' THEN thumbnail_high_url
+      WHEN 'standard' THEN thumbnail_standard_url
+      WHEN 'maxres' THEN thumbnail_maxres_url
+  END = @url::text;
+
+-- name: SetYouTubeVideoThumbnailMissingAt :exec
+-- Records that the image of the thumbnail of the size has been removed from YouTube,
+-- unless the URL of the thumbnail has changed since.
+UPDATE youtube_videos
+SET thumbnail_default_missing_at = CASE WHEN @size::text = 'default' THEN @missing_at::timestamptz ELSE thumbnail_default_missing_at END,
+    thumbnail_medium_missing_at = CASE WHEN @size::text = 'medium' THEN @missing_at::timestamptz ELSE thumbnail_medium_missing_at END,
+    thumbnail_high_missing_at = CASE WHEN @size::text = 'high' THEN @missing_at::timestamptz ELSE thumbnail_high_missing_at END,
+    thumbnail_standard_missing_at = CASE WHEN @size::text = 'standard' THEN @missing_at::timestamptz ELSE thumbnail_standard_missing_at END,
+    thumbnail_maxres_missing_at = CASE WHEN @size::text = 'maxres' THEN @missing_at::timestamptz ELSE thumbnail_maxres_missing_at END
+WHERE video_id = @video_id::text
+  AND CASE @size::text
+      WHEN 'default' THEN thumbnail_default_url
+      WHEN 'medium' THEN thumbnail_medium_url
+      WHEN 'high' THEN thumbnail_high_url
+      WHEN 'standard' THEN thumbnail_standard_url
+      WHEN 'maxres' THEN thumbnail_maxres_url
+  END = @url::text;
+
+-- name: CreateYouTubeVideoThumbnailArchive :execrows
+-- Images archived before are skipped, keeping when they were first archived.
+INSERT INTO youtube_video_thumbnail_archives (video_id, size, sha256, url, archived_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (video_id, size, sha256) DO NOTHING;
+
+-- name: ListYouTubeVideoThumbnailArchives :many
+SELECT * FROM youtube_video_thumbnail_archives
+WHERE video_id = $1
+ORDER BY archived_at, size;
+
+-- name: SetYouTubeVideoThumbnailsCheckedAt :exec
+UPDATE youtube_videos
+SET thumbnails_checked_at = @checked_at::timestamptz
+WHERE video_id = @video_id::text;
+
+-- name: ListYouTubeVideoIDsWithThumbnailsToArchive :many
+-- Lists the videos that have a thumbnail whose image at the current URL is neither archived
+-- nor known to be removed, or whose thumbnails have not been checked since checked_before,
+-- as YouTube may replace the image at the same URL.
+SELECT video_id FROM youtube_videos
+WHERE thumbnails_checked_at IS NULL
+   OR thumbnails_checked_at < @checked_before::timestamptz
+   OR (thumbnail_default_url IS NOT NULL AND thumbnail_default_sha256 IS NULL AND thumbnail_default_missing_at IS NULL)
+   OR (thumbnail_medium_url IS NOT NULL AND thumbnail_medium_sha256 IS NULL AND thumbnail_medium_missing_at IS NULL)
+   OR (thumbnail_high_url IS NOT NULL AND thumbnail_high_sha256 IS NULL AND thumbnail_high_missing_at IS NULL)
+   OR (thumbnail_standard_url IS NOT NULL AND thumbnail_standard_sha256 IS NULL AND thumbnail_standard_missing_at IS NULL)
+   OR (thumbnail_maxres_url IS NOT NULL AND thumbnail_maxres_sha256 IS NULL AND thumbnail_maxres_missing_at IS NULL)
+ORDER BY published_at DESC, video_id;
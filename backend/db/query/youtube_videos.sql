@@ -2,17 +2,95 @@
 INSERT INTO youtube_videos (
     video_id, title, description, duration,
     thumbnail_default_url,thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
-    published_at
+    published_at, broadcast_state,
+    tags, category_id, default_audio_language, privacy_status, made_for_kids,
+    region_restriction_allowed, region_restriction_blocked, has_caption, definition, class
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);
+
+-- name: UpsertYouTubeVideo :one
+-- A premiere is reported as a completed broadcast once it ends, so it is kept as a premiere, class included.
+-- A short is kept as a short when it is saved as an upload, since only a full sync can tell shorts
+-- that look like uploads apart, from the shorts playlist of the channel.
+-- The hash of an archived thumbnail, and the record of it being removed, are cleared when its URL changes,
+-- since they no longer apply.
+INSERT INTO youtube_videos (
+    video_id, title, description, duration,
+    thumbnail_default_url,thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
+    published_at, broadcast_state,
+    tags, category_id, default_audio_language, privacy_status, made_for_kids,
+    region_restriction_allowed, region_restriction_blocked, has_caption, definition, class
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+ON CONFLICT (video_id) DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    duration = EXCLUDED.duration,
+    thumbnail_default_url = EXCLUDED.thumbnail_default_url,
+    thumbnail_medium_url = EXCLUDED.thumbnail_medium_url,
+    thumbnail_high_url = EXCLUDED.thumbnail_high_url,
+    thumbnail_standard_url = EXCLUDED.thumbnail_standard_url,
+    thumbnail_maxres_url = EXCLUDED.thumbnail_maxres_url,
+    thumbnail_default_sha256 = CASE WHEN youtube_videos.thumbnail_default_url IS DISTINCT FROM EXCLUDED.thumbnail_default_url THEN NULL ELSE youtube_videos.thumbnail_default_sha256 END,
+    thumbnail_medium_sha256 = CASE WHEN youtube_videos.thumbnail_medium_url IS DISTINCT FROM EXCLUDED.thumbnail_medium_url THEN NULL ELSE youtube_videos.thumbnail_medium_sha256 END,
+    thumbnail_high_sha256 = CASE WHEN youtube_videos.thumbnail_high_url IS DISTINCT FROM EXCLUDED.thumbnail_high_url THEN NULL ELSE youtube_videos.thumbnail_high_sha256 END,
+    thumbnail_standard_sha256 = CASE WHEN youtube_videos.thumbnail_standard_url IS DISTINCT FROM EXCLUDED.thumbnail_standard_url THEN NULL ELSE youtube_videos.thumbnail_standard_sha256 END,
+    thumbnail_maxres_sha256 = CASE WHEN youtube_videos.thumbnail_maxres_url IS DISTINCT FROM EXCLUDED.thumbnail_maxres_url THEN NULL ELSE youtube_videos.thumbnail_maxres_sha256 END,
+    thumbnail_default_missing_at = CASE WHEN youtube_videos.thumbnail_default_url IS DISTINCT FROM EXCLUDED.thumbnail_default_url THEN NULL ELSE youtube_videos.thumbnail_default_missing_at END,
+    thumbnail_medium_missing_at = CASE WHEN youtube_videos.thumbnail_medium_url IS DISTINCT FROM EXCLUDED.thumbnail_medium_url THEN NULL ELSE youtube_videos.thumbnail_medium_missing_at END,
+    thumbnail_high_missing_at = CASE WHEN youtube_videos.thumbnail_high_url IS DISTINCT FROM EXCLUDED.thumbnail_high_url THEN NULL ELSE youtube_videos.thumbnail_high_missing_at END,
+    thumbnail_standard_missing_at = CASE WHEN youtube_videos.thumbnail_standard_url IS DISTINCT FROM EXCLUDED.thumbnail_standard_url THEN NULL ELSE youtube_videos.thumbnail_standard_missing_at END,
+    thumbnail_maxres_missing_at = CASE WHEN youtube_videos.thumbnail_maxres_url IS DISTINCT FROM EXCLUDED.thumbnail_maxres_url THEN NULL ELSE youtube_videos.thumbnail_maxres_missing_at END,
+    published_at = EXCLUDED.published_at,
+    broadcast_state = CASE
+        WHEN youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed' THEN youtube_videos.broadcast_state
+        ELSE EXCLUDED.broadcast_state
+    END,
+    tags = EXCLUDED.tags,
+    category_id = EXCLUDED.category_id,
+    default_audio_language = EXCLUDED.default_audio_language,
+    privacy_status = EXCLUDED.privacy_status,
+    made_for_kids = EXCLUDED.made_for_kids,
+    region_restriction_allowed = EXCLUDED.region_restriction_allowed,
+    region_restriction_blocked = EXCLUDED.region_restriction_blocked,
+    has_caption = EXCLUDED.has_caption,
+    definition = EXCLUDED.definition,
+    class = CASE
+        WHEN youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed' THEN youtube_videos.class
+        WHEN youtube_videos.class = 'short' AND EXCLUDED.class = 'upload' THEN youtube_videos.class
+        ELSE EXCLUDED.class
+    END
+WHERE (
+    youtube_videos.title, youtube_videos.description, youtube_videos.duration,
+    youtube_videos.thumbnail_default_url, youtube_videos.thumbnail_medium_url, youtube_videos.thumbnail_high_url, youtube_videos.thumbnail_standard_url, youtube_videos.thumbnail_maxres_url,
+    youtube_videos.published_at,
+    youtube_videos.tags, youtube_videos.category_id, youtube_videos.default_audio_language, youtube_videos.privacy_status, youtube_videos.made_for_kids,
+    youtube_videos.region_restriction_allowed, youtube_videos.region_restriction_blocked, youtube_videos.has_caption, youtube_videos.definition
+) IS DISTINCT FROM (
+    EXCLUDED.title, EXCLUDED.description, EXCLUDED.duration,
+    EXCLUDED.thumbnail_default_url, EXCLUDED.thumbnail_medium_url, EXCLUDED.thumbnail_high_url, EXCLUDED.thumbnail_standard_url, EXCLUDED.thumbnail_maxres_url,
+    EXCLUDED.published_at,
+    EXCLUDED.tags, EXCLUDED.category_id, EXCLUDED.default_audio_language, EXCLUDED.privacy_status, EXCLUDED.made_for_kids,
+    EXCLUDED.region_restriction_allowed, EXCLUDED.region_restriction_blocked, EXCLUDED.has_caption, EXCLUDED.definition
+) OR (
+    youtube_videos.broadcast_state <> EXCLUDED.broadcast_state
+    AND NOT (youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed')
+) OR (
+    youtube_videos.class <> EXCLUDED.class
+    AND NOT (youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed')
+    AND NOT (youtube_videos.class = 'short' AND EXCLUDED.class = 'upload')
+)
+RETURNING (xmax = 0) AS inserted;
 
 -- name: GetYouTubeVideo :one
 SELECT * FROM youtube_videos
 WHERE video_id = $1;
 
 -- name: ListYouTubeVideos :many
+-- Lists the videos with the given IDs, only of the given class unless it is NULL.
 SELECT * FROM youtube_videos
-WHERE video_id = ANY(@video_ids::text[]);
+WHERE video_id = ANY(@video_ids::text[])
+    AND (sqlc.narg('class')::text IS NULL OR class = sqlc.narg('class')::text);
 
 -- name: CreateYouTubeVideoLiveStreamingDetails :exec
 INSERT INTO youtube_video_live_streaming_details (
@@ -20,6 +98,51 @@ INSERT INTO youtube_video_live_streaming_details (
 )
 VALUES ($1, $2, $3, $4);
 
+-- name: UpsertYouTubeVideoLiveStreamingDetails :one
+INSERT INTO youtube_video_live_streaming_details (
+    video_id, actual_start_time, actual_end_time, scheduled_start_time
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (video_id) DO UPDATE
+SET actual_start_time = EXCLUDED.actual_start_time,
+    actual_end_time = EXCLUDED.actual_end_time,
+    scheduled_start_time = EXCLUDED.scheduled_start_time
+WHERE (
+    youtube_video_live_streaming_details.actual_start_time,
+    youtube_video_live_streaming_details.actual_end_time,
+    youtube_video_live_streaming_details.scheduled_start_time
+) IS DISTINCT FROM (
+    EXCLUDED.actual_start_time,
+    EXCLUDED.actual_end_time,
+    EXCLUDED.scheduled_start_time
+)
+RETURNING (xmax = 0) AS inserted;
+
 -- name: GetYouTubeVideoLiveStreamingDetails :one
 SELECT * FROM youtube_video_live_streaming_details
 WHERE video_id = $1;
+
+-- name: ListYouTubeVideoIDsByChannel :many
+SELECT video_id FROM youtube_videos
+WHERE video_id IN (
+    SELECT pv.video_id FROM youtube_playlist_videos pv
+    JOIN youtube_playlists p ON p.playlist_id = pv.playlist_id
+    WHERE p.channel_id = $1
+)
+ORDER BY video_id;
+
+-- name: UpdateYouTubeVideoAvailability :execrows
+-- Updates nothing if the video already has the availability.
+UPDATE youtube_videos
+SET availability = @availability::text
+WHERE video_id = @video_id::text
+    AND availability <> @availability::text;
+
+-- name: ListLiveYouTubeVideoIDs :many
+-- Lists the broadcasts that started since `since` but have not ended,
+-- and the upcoming ones scheduled to start in [since, until].
+SELECT video_id FROM youtube_video_live_streaming_details
+WHERE actual_end_time IS NULL
+  AND (actual_start_time >= @since::timestamptz
+    OR (actual_start_time IS NULL AND scheduled_start_time BETWEEN @since::timestamptz AND @until::timestamptz))
+ORDER BY scheduled_start_time, video_id;
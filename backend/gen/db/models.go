@@ -8,39 +8,184 @@ import (
 	"time"
 )
 
+type YoutubeApiQuotaUsage struct {
+	UsageDate time.Time
+	Units     int64
+}
+
+type YoutubeApiResponseCache struct {
+	CacheKey  string
+	Etag      string
+	Body      []byte
+	UpdatedAt time.Time
+}
+
+type YoutubeCaptionSegment struct {
+	CaptionID   string
+	Seq         int32
+	StartOffset time.Duration
+	EndOffset   time.Duration
+	Text        string
+}
+
+type YoutubeCaptionTrack struct {
+	CaptionID         string
+	VideoID           string
+	Language          string
+	Name              string
+	TrackKind         string
+	UpdatedAt         time.Time
+	SegmentsUpdatedAt *time.Time
+}
+
 type YoutubeChannel struct {
 	ChannelID         string
 	Handle            string
 	UploadsPlaylistID string
+	Title             string
+	Description       string
+	Country           string
+	AvatarUrl         *string
+	BannerUrl         *string
+	PublishedAt       time.Time
 }
 
-type YoutubePlaylist struct {
-	PlaylistID string
-	ChannelID  string
-	Title      string
+type YoutubeChannelSnapshot struct {
+	ChannelID       string
+	CapturedAt      time.Time
+	Title           string
+	Description     string
+	AvatarUrl       *string
+	BannerUrl       *string
+	SubscriberCount *int64
+	ViewCount       int64
+	VideoCount      int64
 }
 
-type YoutubePlaylistVideo struct {
-	PlaylistID string
-	VideoID    string
+type YoutubeComment struct {
+	CommentID         string
+	VideoID           string
+	ParentID          *string
+	AuthorDisplayName string
+	AuthorChannelID   string
+	Text              string
+	LikeCount         int64
+	ReplyCount        int64
+	PublishedAt       time.Time
+	UpdatedAt         time.Time
 }
 
-type YoutubeVideo struct {
-	VideoID              string
+type YoutubeCommentSync struct {
+	VideoID     string
+	SyncedAt    time.Time
+	RefreshedAt time.Time
+}
+
+type YoutubeLiveChatMessage struct {
+	MessageID              string
+	VideoID                string
+	MessageType            string
+	AuthorChannelID        string
+	AuthorDisplayName      string
+	AuthorIsOwner          bool
+	AuthorIsModerator      bool
+	AuthorIsMember         bool
+	Text                   string
+	PublishedAt            time.Time
+	SuperChatAmountMicros  *int64
+	SuperChatCurrency      *string
+	SuperChatAmountDisplay *string
+	SuperChatTier          *int64
+	MemberLevelName        *string
+	MemberMonths           *int64
+	GiftCount              *int64
+}
+
+type YoutubePlaylist struct {
+	PlaylistID           string
+	ChannelID            string
 	Title                string
 	Description          string
-	Duration             time.Duration
 	ThumbnailDefaultUrl  *string
 	ThumbnailMediumUrl   *string
 	ThumbnailHighUrl     *string
 	ThumbnailStandardUrl *string
 	ThumbnailMaxresUrl   *string
+	ItemCount            int64
+	PrivacyStatus        string
 	PublishedAt          time.Time
 }
 
+type YoutubePlaylistVideo struct {
+	PlaylistID       string
+	VideoID          string
+	AddedAt          time.Time
+	RemovedAt        *time.Time
+	Position         int32
+	VideoPublishedAt *time.Time
+}
+
+type YoutubeVideo struct {
+	VideoID                    string
+	Title                      string
+	Description                string
+	Duration                   time.Duration
+	ThumbnailDefaultUrl        *string
+	ThumbnailMediumUrl         *string
+	ThumbnailHighUrl           *string
+	ThumbnailStandardUrl       *string
+	ThumbnailMaxresUrl         *string
+	PublishedAt                time.Time
+	BroadcastState             string
+	Tags                       []string
+	CategoryID                 string
+	DefaultAudioLanguage       string
+	PrivacyStatus              string
+	MadeForKids                bool
+	RegionRestrictionAllowed   []string
+	RegionRestrictionBlocked   []string
+	HasCaption                 bool
+	Definition                 string
+	Availability               string
+	Class                      string
+	ThumbnailDefaultSha256     *string
+	ThumbnailMediumSha256      *string
+	ThumbnailHighSha256        *string
+	ThumbnailStandardSha256    *string
+	ThumbnailMaxresSha256      *string
+	ThumbnailDefaultMissingAt  *time.Time
+	ThumbnailMediumMissingAt   *time.Time
+	ThumbnailHighMissingAt     *time.Time
+	ThumbnailStandardMissingAt *time.Time
+	ThumbnailMaxresMissingAt   *time.Time
+	ThumbnailsCheckedAt        *time.Time
+}
+
+type YoutubeVideoAvailabilityChange struct {
+	VideoID      string
+	ObservedAt   time.Time
+	Availability string
+}
+
 type YoutubeVideoLiveStreamingDetail struct {
 	VideoID            string
-	ActualStartTime    time.Time
-	ActualEndTime      time.Time
+	ActualStartTime    *time.Time
+	ActualEndTime      *time.Time
 	ScheduledStartTime time.Time
 }
+
+type YoutubeVideoStatistic struct {
+	VideoID      string
+	CapturedAt   time.Time
+	ViewCount    int64
+	LikeCount    int64
+	CommentCount int64
+}
+
+type YoutubeVideoThumbnailArchive struct {
+	VideoID    string
+	Size       string
+	Sha256     string
+	Url        string
+	ArchivedAt time.Time
+}
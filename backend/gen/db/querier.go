@@ -6,23 +6,99 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	CreateYouTubeCaptionSegment(ctx context.Context, arg CreateYouTubeCaptionSegmentParams) error
 	CreateYouTubeChannel(ctx context.Context, arg CreateYouTubeChannelParams) error
+	CreateYouTubeChannelSnapshot(ctx context.Context, arg CreateYouTubeChannelSnapshotParams) error
+	// Messages already saved are skipped, since polling may return them again.
+	CreateYouTubeLiveChatMessage(ctx context.Context, arg CreateYouTubeLiveChatMessageParams) (int64, error)
 	CreateYouTubePlaylist(ctx context.Context, arg CreateYouTubePlaylistParams) error
 	CreateYouTubePlaylistVideo(ctx context.Context, arg CreateYouTubePlaylistVideoParams) error
 	CreateYouTubeVideo(ctx context.Context, arg CreateYouTubeVideoParams) error
+	CreateYouTubeVideoAvailabilityChange(ctx context.Context, arg CreateYouTubeVideoAvailabilityChangeParams) error
 	CreateYouTubeVideoLiveStreamingDetails(ctx context.Context, arg CreateYouTubeVideoLiveStreamingDetailsParams) error
+	CreateYouTubeVideoStatistics(ctx context.Context, arg CreateYouTubeVideoStatisticsParams) error
+	// Images archived before are skipped, keeping when they were first archived.
+	CreateYouTubeVideoThumbnailArchive(ctx context.Context, arg CreateYouTubeVideoThumbnailArchiveParams) (int64, error)
+	DeleteYouTubeCaptionSegments(ctx context.Context, captionID string) error
+	// Memberships saved before added_at was recorded have it at the epoch.
+	// The first sync that lists the video fills it in, instead of opening a second membership.
+	FillYouTubePlaylistVideoAddedAt(ctx context.Context, arg FillYouTubePlaylistVideoAddedAtParams) error
+	GetLatestYouTubeChannelSnapshot(ctx context.Context, channelID string) (YoutubeChannelSnapshot, error)
+	GetLatestYouTubeVideoStatistics(ctx context.Context, videoID string) (YoutubeVideoStatistic, error)
+	GetYouTubeAPIQuotaUsage(ctx context.Context, usageDate time.Time) (int64, error)
+	GetYouTubeAPIResponseCache(ctx context.Context, cacheKey string) (YoutubeApiResponseCache, error)
 	GetYouTubeChannel(ctx context.Context, channelID string) (YoutubeChannel, error)
 	GetYouTubeChannelByHandle(ctx context.Context, handle string) (YoutubeChannel, error)
+	GetYouTubeCommentSync(ctx context.Context, videoID string) (YoutubeCommentSync, error)
 	GetYouTubePlaylist(ctx context.Context, playlistID string) (YoutubePlaylist, error)
 	GetYouTubeVideo(ctx context.Context, videoID string) (YoutubeVideo, error)
 	GetYouTubeVideoLiveStreamingDetails(ctx context.Context, videoID string) (YoutubeVideoLiveStreamingDetail, error)
+	// Ranks videos by the views they gained between the first and last statistics captured in [since, until).
+	ListFastestGrowingYouTubeVideos(ctx context.Context, arg ListFastestGrowingYouTubeVideosParams) ([]ListFastestGrowingYouTubeVideosRow, error)
+	// Lists the broadcasts that started since `since` but have not ended,
+	// and the upcoming ones scheduled to start in [since, until].
+	ListLiveYouTubeVideoIDs(ctx context.Context, arg ListLiveYouTubeVideoIDsParams) ([]string, error)
 	ListPlaylistIDsByChannel(ctx context.Context, channelID string) ([]string, error)
 	ListPlaylists(ctx context.Context, playlistIds []string) ([]YoutubePlaylist, error)
-	ListYouTubePlaylistVideoIDs(ctx context.Context, playlistID string) ([]string, error)
-	ListYouTubeVideos(ctx context.Context, videoIds []string) ([]YoutubeVideo, error)
+	// Lists the tracks whose segments have not been saved since the track was last updated.
+	ListStaleYouTubeCaptionTracksByVideo(ctx context.Context, videoID string) ([]YoutubeCaptionTrack, error)
+	ListYouTubeCaptionSegments(ctx context.Context, captionID string) ([]YoutubeCaptionSegment, error)
+	ListYouTubeCaptionTracksByVideo(ctx context.Context, videoID string) ([]YoutubeCaptionTrack, error)
+	// Lists the snapshots captured in [since, until), oldest first.
+	ListYouTubeChannelSnapshots(ctx context.Context, arg ListYouTubeChannelSnapshotsParams) ([]YoutubeChannelSnapshot, error)
+	ListYouTubeCommentsByVideo(ctx context.Context, videoID string) ([]YoutubeComment, error)
+	ListYouTubeLiveChatMessages(ctx context.Context, videoID string) ([]YoutubeLiveChatMessage, error)
+	// Lists the videos currently in the playlist in playlist order, only of the given class unless it is NULL.
+	ListYouTubePlaylistVideoIDs(ctx context.Context, arg ListYouTubePlaylistVideoIDsParams) ([]string, error)
+	// Lists every membership of the playlist, including past ones, oldest first.
+	ListYouTubePlaylistVideos(ctx context.Context, playlistID string) ([]YoutubePlaylistVideo, error)
+	ListYouTubeVideoAvailabilityChanges(ctx context.Context, videoID string) ([]YoutubeVideoAvailabilityChange, error)
+	ListYouTubeVideoIDsByChannel(ctx context.Context, channelID string) ([]string, error)
+	// Lists the videos that have a thumbnail whose image at the current URL is neither archived
+	// nor known to be removed, or whose thumbnails have not been checked since checked_before,
+	// as YouTube may replace the image at the same URL.
+	ListYouTubeVideoIDsWithThumbnailsToArchive(ctx context.Context, checkedBefore time.Time) ([]string, error)
+	// Lists the statistics captured in [since, until), oldest first.
+	ListYouTubeVideoStatistics(ctx context.Context, arg ListYouTubeVideoStatisticsParams) ([]YoutubeVideoStatistic, error)
+	ListYouTubeVideoThumbnailArchives(ctx context.Context, videoID string) ([]YoutubeVideoThumbnailArchive, error)
+	// Lists the videos with the given IDs, only of the given class unless it is NULL.
+	ListYouTubeVideos(ctx context.Context, arg ListYouTubeVideosParams) ([]YoutubeVideo, error)
+	// Marks the current memberships that are no longer listed in the playlist as removed.
+	// A membership is listed if a video is listed with the same added_at, so a video that was
+	// removed and added again between two syncs has its old membership closed.
+	// Memberships whose added_at was never recorded are matched by the video only.
+	RemoveYouTubePlaylistVideos(ctx context.Context, arg RemoveYouTubePlaylistVideosParams) (int64, error)
+	// Adds units to the usage of the day unless the total would exceed the budget, in which case no row is returned.
+	ReserveYouTubeAPIQuota(ctx context.Context, arg ReserveYouTubeAPIQuotaParams) (int64, error)
+	SetYouTubeCaptionTrackSegmentsUpdatedAt(ctx context.Context, arg SetYouTubeCaptionTrackSegmentsUpdatedAtParams) error
+	// Records that the image of the thumbnail of the size has been removed from YouTube,
+	// unless the URL of the thumbnail has changed since.
+	SetYouTubeVideoThumbnailMissingAt(ctx context.Context, arg SetYouTubeVideoThumbnailMissingAtParams) error
+	// Records the hash of the archived image of the thumbnail of the size,
+	// unless the URL of the thumbnail has changed since the image was downloaded.
+	SetYouTubeVideoThumbnailSHA256(ctx context.Context, arg SetYouTubeVideoThumbnailSHA256Params) error
+	SetYouTubeVideoThumbnailsCheckedAt(ctx context.Context, arg SetYouTubeVideoThumbnailsCheckedAtParams) error
+	// Updates nothing if the video already has the availability.
+	UpdateYouTubeVideoAvailability(ctx context.Context, arg UpdateYouTubeVideoAvailabilityParams) (int64, error)
+	UpsertYouTubeAPIResponseCache(ctx context.Context, arg UpsertYouTubeAPIResponseCacheParams) error
+	UpsertYouTubeCaptionTrack(ctx context.Context, arg UpsertYouTubeCaptionTrackParams) (bool, error)
+	UpsertYouTubeChannel(ctx context.Context, arg UpsertYouTubeChannelParams) (bool, error)
+	UpsertYouTubeComment(ctx context.Context, arg UpsertYouTubeCommentParams) (bool, error)
+	UpsertYouTubeCommentSync(ctx context.Context, arg UpsertYouTubeCommentSyncParams) error
+	UpsertYouTubePlaylist(ctx context.Context, arg UpsertYouTubePlaylistParams) (bool, error)
+	// A membership that was marked removed is reopened if the video turns out to still be in the playlist.
+	UpsertYouTubePlaylistVideo(ctx context.Context, arg UpsertYouTubePlaylistVideoParams) (bool, error)
+	// A premiere is reported as a completed broadcast once it ends, so it is kept as a premiere, class included.
+	// A short is kept as a short when it is saved as an upload, since only a full sync can tell shorts
+	// that look like uploads apart, from the shorts playlist of the channel.
+	// The hash of an archived thumbnail, and the record of it being removed, are cleared when its URL changes,
+	// since they no longer apply.
+	UpsertYouTubeVideo(ctx context.Context, arg UpsertYouTubeVideoParams) (bool, error)
+	UpsertYouTubeVideoLiveStreamingDetails(ctx context.Context, arg UpsertYouTubeVideoLiveStreamingDetailsParams) (bool, error)
 }
 
 var _ Querier = (*Queries)(nil)
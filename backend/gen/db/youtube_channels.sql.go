@@ -7,44 +7,127 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createYouTubeChannel = `-- name: CreateYouTubeChannel :exec
-INSERT INTO youtube_channels (channel_id, handle, uploads_playlist_id)
-VALUES ($1, $2, $3)
+INSERT INTO youtube_channels (channel_id, handle, uploads_playlist_id, title, description, country, avatar_url, banner_url, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 `
 
 type CreateYouTubeChannelParams struct {
 	ChannelID         string
 	Handle            string
 	UploadsPlaylistID string
+	Title             string
+	Description       string
+	Country           string
+	AvatarUrl         *string
+	BannerUrl         *string
+	PublishedAt       time.Time
 }
 
 func (q *Queries) CreateYouTubeChannel(ctx context.Context, arg CreateYouTubeChannelParams) error {
-	_, err := q.db.Exec(ctx, createYouTubeChannel, arg.ChannelID, arg.Handle, arg.UploadsPlaylistID)
+	_, err := q.db.Exec(ctx, createYouTubeChannel,
+		arg.ChannelID,
+		arg.Handle,
+		arg.UploadsPlaylistID,
+		arg.Title,
+		arg.Description,
+		arg.Country,
+		arg.AvatarUrl,
+		arg.BannerUrl,
+		arg.PublishedAt,
+	)
 	return err
 }
 
 const getYouTubeChannel = `-- name: GetYouTubeChannel :one
-SELECT channel_id, handle, uploads_playlist_id FROM youtube_channels
+SELECT channel_id, handle, uploads_playlist_id, title, description, country, avatar_url, banner_url, published_at FROM youtube_channels
 WHERE channel_id = $1
 `
 
 func (q *Queries) GetYouTubeChannel(ctx context.Context, channelID string) (YoutubeChannel, error) {
 	row := q.db.QueryRow(ctx, getYouTubeChannel, channelID)
 	var i YoutubeChannel
-	err := row.Scan(&i.ChannelID, &i.Handle, &i.UploadsPlaylistID)
+	err := row.Scan(
+		&i.ChannelID,
+		&i.Handle,
+		&i.UploadsPlaylistID,
+		&i.Title,
+		&i.Description,
+		&i.Country,
+		&i.AvatarUrl,
+		&i.BannerUrl,
+		&i.PublishedAt,
+	)
 	return i, err
 }
 
 const getYouTubeChannelByHandle = `-- name: GetYouTubeChannelByHandle :one
-SELECT channel_id, handle, uploads_playlist_id FROM youtube_channels
+SELECT channel_id, handle, uploads_playlist_id, title, description, country, avatar_url, banner_url, published_at FROM youtube_channels
 WHERE handle = $1
 `
 
 func (q *Queries) GetYouTubeChannelByHandle(ctx context.Context, handle string) (YoutubeChannel, error) {
 	row := q.db.QueryRow(ctx, getYouTubeChannelByHandle, handle)
 	var i YoutubeChannel
-	err := row.Scan(&i.ChannelID, &i.Handle, &i.UploadsPlaylistID)
+	err := row.Scan(
+		&i.ChannelID,
+		&i.Handle,
+		&i.UploadsPlaylistID,
+		&i.Title,
+		&i.Description,
+		&i.Country,
+		&i.AvatarUrl,
+		&i.BannerUrl,
+		&i.PublishedAt,
+	)
 	return i, err
 }
+
+const upsertYouTubeChannel = `-- name: UpsertYouTubeChannel :one
+INSERT INTO youtube_channels (channel_id, handle, uploads_playlist_id, title, description, country, avatar_url, banner_url, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (channel_id) DO UPDATE
+SET handle = EXCLUDED.handle,
+    uploads_playlist_id = EXCLUDED.uploads_playlist_id,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    country = EXCLUDED.country,
+    avatar_url = EXCLUDED.avatar_url,
+    banner_url = EXCLUDED.banner_url,
+    published_at = EXCLUDED.published_at
+WHERE (youtube_channels.handle, youtube_channels.uploads_playlist_id, youtube_channels.title, youtube_channels.description, youtube_channels.country, youtube_channels.avatar_url, youtube_channels.banner_url, youtube_channels.published_at)
+    IS DISTINCT FROM (EXCLUDED.handle, EXCLUDED.uploads_playlist_id, EXCLUDED.title, EXCLUDED.description, EXCLUDED.country, EXCLUDED.avatar_url, EXCLUDED.banner_url, EXCLUDED.published_at)
+RETURNING (xmax = 0) AS inserted
+`
+
+type UpsertYouTubeChannelParams struct {
+	ChannelID         string
+	Handle            string
+	UploadsPlaylistID string
+	Title             string
+	Description       string
+	Country           string
+	AvatarUrl         *string
+	BannerUrl         *string
+	PublishedAt       time.Time
+}
+
+func (q *Queries) UpsertYouTubeChannel(ctx context.Context, arg UpsertYouTubeChannelParams) (bool, error) {
+	row := q.db.QueryRow(ctx, upsertYouTubeChannel,
+		arg.ChannelID,
+		arg.Handle,
+		arg.UploadsPlaylistID,
+		arg.Title,
+		arg.Description,
+		arg.Country,
+		arg.AvatarUrl,
+		arg.BannerUrl,
+		arg.PublishedAt,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
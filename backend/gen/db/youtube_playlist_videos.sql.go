@@ -7,30 +7,76 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createYouTubePlaylistVideo = `-- name: CreateYouTubePlaylistVideo :exec
-INSERT INTO youtube_playlist_videos (playlist_id, video_id)
-VALUES ($1, $2)
+INSERT INTO youtube_playlist_videos (playlist_id, video_id, added_at, position, video_published_at)
+VALUES ($1, $2, $3, $4, $5)
 `
 
 type CreateYouTubePlaylistVideoParams struct {
+	PlaylistID       string
+	VideoID          string
+	AddedAt          time.Time
+	Position         int32
+	VideoPublishedAt *time.Time
+}
+
+func (q *Queries) CreateYouTubePlaylistVideo(ctx context.Context, arg CreateYouTubePlaylistVideoParams) error {
+	_, err := q.db.Exec(ctx, createYouTubePlaylistVideo,
+		arg.PlaylistID,
+		arg.VideoID,
+		arg.AddedAt,
+		arg.Position,
+		arg.VideoPublishedAt,
+	)
+	return err
+}
+
+const fillYouTubePlaylistVideoAddedAt = `-- name: FillYouTubePlaylistVideoAddedAt :exec
+UPDATE youtube_playlist_videos pv
+SET added_at = $1::timestamptz
+WHERE pv.playlist_id = $2::text
+    AND pv.video_id = $3::text
+    AND pv.added_at = 'epoch'
+    AND pv.removed_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM youtube_playlist_videos other
+        WHERE other.playlist_id = pv.playlist_id AND other.video_id = pv.video_id AND other.added_at = $1::timestamptz
+    )
+`
+
+type FillYouTubePlaylistVideoAddedAtParams struct {
+	AddedAt    time.Time
 	PlaylistID string
 	VideoID    string
 }
 
-func (q *Queries) CreateYouTubePlaylistVideo(ctx context.Context, arg CreateYouTubePlaylistVideoParams) error {
-	_, err := q.db.Exec(ctx, createYouTubePlaylistVideo, arg.PlaylistID, arg.VideoID)
+// Memberships saved before added_at was recorded have it at the epoch.
+// The first sync that lists the video fills it in, instead of opening a second membership.
+func (q *Queries) FillYouTubePlaylistVideoAddedAt(ctx context.Context, arg FillYouTubePlaylistVideoAddedAtParams) error {
+	_, err := q.db.Exec(ctx, fillYouTubePlaylistVideoAddedAt, arg.AddedAt, arg.PlaylistID, arg.VideoID)
 	return err
 }
 
 const listYouTubePlaylistVideoIDs = `-- name: ListYouTubePlaylistVideoIDs :many
-SELECT video_id FROM youtube_playlist_videos
-WHERE playlist_id = $1
+SELECT pv.video_id FROM youtube_playlist_videos pv
+JOIN youtube_videos v ON v.video_id = pv.video_id
+WHERE pv.playlist_id = $1::text
+    AND pv.removed_at IS NULL
+    AND ($2::text IS NULL OR v.class = $2::text)
+ORDER BY pv.position
 `
 
-func (q *Queries) ListYouTubePlaylistVideoIDs(ctx context.Context, playlistID string) ([]string, error) {
-	rows, err := q.db.Query(ctx, listYouTubePlaylistVideoIDs, playlistID)
+type ListYouTubePlaylistVideoIDsParams struct {
+	PlaylistID string
+	Class      *string
+}
+
+// Lists the videos currently in the playlist in playlist order, only of the given class unless it is NULL.
+func (q *Queries) ListYouTubePlaylistVideoIDs(ctx context.Context, arg ListYouTubePlaylistVideoIDsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listYouTubePlaylistVideoIDs, arg.PlaylistID, arg.Class)
 	if err != nil {
 		return nil, err
 	}
@@ -48,3 +94,106 @@ func (q *Queries) ListYouTubePlaylistVideoIDs(ctx context.Context, playlistID st
 	}
 	return items, nil
 }
+
+const listYouTubePlaylistVideos = `-- name: ListYouTubePlaylistVideos :many
+SELECT playlist_id, video_id, added_at, removed_at, position, video_published_at FROM youtube_playlist_videos
+WHERE playlist_id = $1
+ORDER BY added_at, position
+`
+
+// Lists every membership of the playlist, including past ones, oldest first.
+func (q *Queries) ListYouTubePlaylistVideos(ctx context.Context, playlistID string) ([]YoutubePlaylistVideo, error) {
+	rows, err := q.db.Query(ctx, listYouTubePlaylistVideos, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []YoutubePlaylistVideo{}
+	for rows.Next() {
+		var i YoutubePlaylistVideo
+		if err := rows.Scan(
+			&i.PlaylistID,
+			&i.VideoID,
+			&i.AddedAt,
+			&i.RemovedAt,
+			&i.Position,
+			&i.VideoPublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeYouTubePlaylistVideos = `-- name: RemoveYouTubePlaylistVideos :execrows
+UPDATE youtube_playlist_videos pv
+SET removed_at = $1::timestamptz
+WHERE pv.playlist_id = $2::text
+    AND pv.removed_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM unnest($3::text[], $4::timestamptz[]) AS listed (video_id, added_at)
+        WHERE listed.video_id = pv.video_id AND (listed.added_at = pv.added_at OR pv.added_at = 'epoch')
+    )
+`
+
+type RemoveYouTubePlaylistVideosParams struct {
+	RemovedAt      time.Time
+	PlaylistID     string
+	ListedVideoIds []string
+	ListedAddedAts []time.Time
+}
+
+// Marks the current memberships that are no longer listed in the playlist as removed.
+// A membership is listed if a video is listed with the same added_at, so a video that was
+// removed and added again between two syncs has its old membership closed.
+// Memberships whose added_at was never recorded are matched by the video only.
+func (q *Queries) RemoveYouTubePlaylistVideos(ctx context.Context, arg RemoveYouTubePlaylistVideosParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeYouTubePlaylistVideos,
+		arg.RemovedAt,
+		arg.PlaylistID,
+		arg.ListedVideoIds,
+		arg.ListedAddedAts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertYouTubePlaylistVideo = `-- name: UpsertYouTubePlaylistVideo :one
+INSERT INTO youtube_playlist_videos (playlist_id, video_id, added_at, position, video_published_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (playlist_id, video_id, added_at) DO UPDATE
+SET position = EXCLUDED.position,
+    video_published_at = EXCLUDED.video_published_at,
+    removed_at = NULL
+WHERE (youtube_playlist_videos.position, youtube_playlist_videos.video_published_at, youtube_playlist_videos.removed_at)
+    IS DISTINCT FROM (EXCLUDED.position, EXCLUDED.video_published_at, NULL::timestamptz)
+RETURNING (xmax = 0) AS inserted
+`
+
+type UpsertYouTubePlaylistVideoParams struct {
+	PlaylistID       string
+	VideoID          string
+	AddedAt          time.Time
+	Position         int32
+	VideoPublishedAt *time.Time
+}
+
+// A membership that was marked removed is reopened if the video turns out to still be in the playlist.
+func (q *Queries) UpsertYouTubePlaylistVideo(ctx context.Context, arg UpsertYouTubePlaylistVideoParams) (bool, error) {
+	row := q.db.QueryRow(ctx, upsertYouTubePlaylistVideo,
+		arg.PlaylistID,
+		arg.VideoID,
+		arg.AddedAt,
+		arg.Position,
+		arg.VideoPublishedAt,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
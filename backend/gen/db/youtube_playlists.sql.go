@@ -7,33 +7,73 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createYouTubePlaylist = `-- name: CreateYouTubePlaylist :exec
-INSERT INTO youtube_playlists (playlist_id, channel_id, title)
-VALUES ($1, $2, $3)
+INSERT INTO youtube_playlists (
+    playlist_id, channel_id, title, description,
+    thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
+    item_count, privacy_status, published_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 `
 
 type CreateYouTubePlaylistParams struct {
-	PlaylistID string
-	ChannelID  string
-	Title      string
+	PlaylistID           string
+	ChannelID            string
+	Title                string
+	Description          string
+	ThumbnailDefaultUrl  *string
+	ThumbnailMediumUrl   *string
+	ThumbnailHighUrl     *string
+	ThumbnailStandardUrl *string
+	ThumbnailMaxresUrl   *string
+	ItemCount            int64
+	PrivacyStatus        string
+	PublishedAt          time.Time
 }
 
 func (q *Queries) CreateYouTubePlaylist(ctx context.Context, arg CreateYouTubePlaylistParams) error {
-	_, err := q.db.Exec(ctx, createYouTubePlaylist, arg.PlaylistID, arg.ChannelID, arg.Title)
+	_, err := q.db.Exec(ctx, createYouTubePlaylist,
+		arg.PlaylistID,
+		arg.ChannelID,
+		arg.Title,
+		arg.Description,
+		arg.ThumbnailDefaultUrl,
+		arg.ThumbnailMediumUrl,
+		arg.ThumbnailHighUrl,
+		arg.ThumbnailStandardUrl,
+		arg.ThumbnailMaxresUrl,
+		arg.ItemCount,
+		arg.PrivacyStatus,
+		arg.PublishedAt,
+	)
 	return err
 }
 
 const getYouTubePlaylist = `-- name: GetYouTubePlaylist :one
-SELECT playlist_id, channel_id, title FROM youtube_playlists
+SELECT playlist_id, channel_id, title, description, thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url, item_count, privacy_status, published_at FROM youtube_playlists
 WHERE playlist_id = $1
 `
 
 func (q *Queries) GetYouTubePlaylist(ctx context.Context, playlistID string) (YoutubePlaylist, error) {
 	row := q.db.QueryRow(ctx, getYouTubePlaylist, playlistID)
 	var i YoutubePlaylist
-	err := row.Scan(&i.PlaylistID, &i.ChannelID, &i.Title)
+	err := row.Scan(
+		&i.PlaylistID,
+		&i.ChannelID,
+		&i.Title,
+		&i.Description,
+		&i.ThumbnailDefaultUrl,
+		&i.ThumbnailMediumUrl,
+		&i.ThumbnailHighUrl,
+		&i.ThumbnailStandardUrl,
+		&i.ThumbnailMaxresUrl,
+		&i.ItemCount,
+		&i.PrivacyStatus,
+		&i.PublishedAt,
+	)
 	return i, err
 }
 
@@ -63,7 +103,7 @@ func (q *Queries) ListPlaylistIDsByChannel(ctx context.Context, channelID string
 }
 
 const listPlaylists = `-- name: ListPlaylists :many
-SELECT playlist_id, channel_id, title FROM youtube_playlists
+SELECT playlist_id, channel_id, title, description, thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url, item_count, privacy_status, published_at FROM youtube_playlists
 WHERE playlist_id = ANY($1::text[])
 `
 
@@ -76,7 +116,20 @@ func (q *Queries) ListPlaylists(ctx context.Context, playlistIds []string) ([]Yo
 	items := []YoutubePlaylist{}
 	for rows.Next() {
 		var i YoutubePlaylist
-		if err := rows.Scan(&i.PlaylistID, &i.ChannelID, &i.Title); err != nil {
+		if err := rows.Scan(
+			&i.PlaylistID,
+			&i.ChannelID,
+			&i.Title,
+			&i.Description,
+			&i.ThumbnailDefaultUrl,
+			&i.ThumbnailMediumUrl,
+			&i.ThumbnailHighUrl,
+			&i.ThumbnailStandardUrl,
+			&i.ThumbnailMaxresUrl,
+			&i.ItemCount,
+			&i.PrivacyStatus,
+			&i.PublishedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -86,3 +139,69 @@ func (q *Queries) ListPlaylists(ctx context.Context, playlistIds []string) ([]Yo
 	}
 	return items, nil
 }
+
+const upsertYouTubePlaylist = `-- name: UpsertYouTubePlaylist :one
+INSERT INTO youtube_playlists (
+    playlist_id, channel_id, title, description,
+    thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
+    item_count, privacy_status, published_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (playlist_id) DO UPDATE
+SET channel_id = EXCLUDED.channel_id,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    thumbnail_default_url = EXCLUDED.thumbnail_default_url,
+    thumbnail_medium_url = EXCLUDED.thumbnail_medium_url,
+    thumbnail_high_url = EXCLUDED.thumbnail_high_url,
+    thumbnail_standard_url = EXCLUDED.thumbnail_standard_url,
+    thumbnail_maxres_url = EXCLUDED.thumbnail_maxres_url,
+    item_count = EXCLUDED.item_count,
+    privacy_status = EXCLUDED.privacy_status,
+    published_at = EXCLUDED.published_at
+WHERE (
+    youtube_playlists.channel_id, youtube_playlists.title, youtube_playlists.description,
+    youtube_playlists.thumbnail_default_url, youtube_playlists.thumbnail_medium_url, youtube_playlists.thumbnail_high_url, youtube_playlists.thumbnail_standard_url, youtube_playlists.thumbnail_maxres_url,
+    youtube_playlists.item_count, youtube_playlists.privacy_status, youtube_playlists.published_at
+) IS DISTINCT FROM (
+    EXCLUDED.channel_id, EXCLUDED.title, EXCLUDED.description,
+    EXCLUDED.thumbnail_default_url, EXCLUDED.thumbnail_medium_url, EXCLUDED.thumbnail_high_url, EXCLUDED.thumbnail_standard_url, EXCLUDED.thumbnail_maxres_url,
+    EXCLUDED.item_count, EXCLUDED.privacy_status, EXCLUDED.published_at
+)
+RETURNING (xmax = 0) AS inserted
+`
+
+type UpsertYouTubePlaylistParams struct {
+	PlaylistID           string
+	ChannelID            string
+	Title                string
+	Description          string
+	ThumbnailDefaultUrl  *string
+	ThumbnailMediumUrl   *string
+	ThumbnailHighUrl     *string
+	ThumbnailStandardUrl *string
+	ThumbnailMaxresUrl   *string
+	ItemCount            int64
+	PrivacyStatus        string
+	PublishedAt          time.Time
+}
+
+func (q *Queries) UpsertYouTubePlaylist(ctx context.Context, arg UpsertYouTubePlaylistParams) (bool, error) {
+	row := q.db.QueryRow(ctx, upsertYouTubePlaylist,
+		arg.PlaylistID,
+		arg.ChannelID,
+		arg.Title,
+		arg.Description,
+		arg.ThumbnailDefaultUrl,
+		arg.ThumbnailMediumUrl,
+		arg.ThumbnailHighUrl,
+		arg.ThumbnailStandardUrl,
+		arg.ThumbnailMaxresUrl,
+		arg.ItemCount,
+		arg.PrivacyStatus,
+		arg.PublishedAt,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
@@ -14,22 +14,35 @@ const createYouTubeVideo = `-- name: CreateYouTubeVideo :exec
 INSERT INTO youtube_videos (
     video_id, title, description, duration,
     thumbnail_default_url,thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
-    published_at
+    published_at, broadcast_state,
+    tags, category_id, default_audio_language, privacy_status, made_for_kids,
+    region_restriction_allowed, region_restriction_blocked, has_caption, definition, class
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 `
 
 type CreateYouTubeVideoParams struct {
-	VideoID              string
-	Title                string
-	Description          string
-	Duration             time.Duration
-	ThumbnailDefaultUrl  *string
-	ThumbnailMediumUrl   *string
-	ThumbnailHighUrl     *string
-	ThumbnailStandardUrl *string
-	ThumbnailMaxresUrl   *string
-	PublishedAt          time.Time
+	VideoID                  string
+	Title                    string
+	Description              string
+	Duration                 time.Duration
+	ThumbnailDefaultUrl      *string
+	ThumbnailMediumUrl       *string
+	ThumbnailHighUrl         *string
+	ThumbnailStandardUrl     *string
+	ThumbnailMaxresUrl       *string
+	PublishedAt              time.Time
+	BroadcastState           string
+	Tags                     []string
+	CategoryID               string
+	DefaultAudioLanguage     string
+	PrivacyStatus            string
+	MadeForKids              bool
+	RegionRestrictionAllowed []string
+	RegionRestrictionBlocked []string
+	HasCaption               bool
+	Definition               string
+	Class                    string
 }
 
 func (q *Queries) CreateYouTubeVideo(ctx context.Context, arg CreateYouTubeVideoParams) error {
@@ -44,6 +57,17 @@ func (q *Queries) CreateYouTubeVideo(ctx context.Context, arg CreateYouTubeVideo
 		arg.ThumbnailStandardUrl,
 		arg.ThumbnailMaxresUrl,
 		arg.PublishedAt,
+		arg.BroadcastState,
+		arg.Tags,
+		arg.CategoryID,
+		arg.DefaultAudioLanguage,
+		arg.PrivacyStatus,
+		arg.MadeForKids,
+		arg.RegionRestrictionAllowed,
+		arg.RegionRestrictionBlocked,
+		arg.HasCaption,
+		arg.Definition,
+		arg.Class,
 	)
 	return err
 }
@@ -57,8 +81,8 @@ VALUES ($1, $2, $3, $4)
 
 type CreateYouTubeVideoLiveStreamingDetailsParams struct {
 	VideoID            string
-	ActualStartTime    time.Time
-	ActualEndTime      time.Time
+	ActualStartTime    *time.Time
+	ActualEndTime      *time.Time
 	ScheduledStartTime time.Time
 }
 
@@ -73,7 +97,7 @@ func (q *Queries) CreateYouTubeVideoLiveStreamingDetails(ctx context.Context, ar
 }
 
 const getYouTubeVideo = `-- name: GetYouTubeVideo :one
-SELECT video_id, title, description, duration, thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url, published_at FROM youtube_videos
+SELECT video_id, title, description, duration, thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url, published_at, broadcast_state, tags, category_id, default_audio_language, privacy_status, made_for_kids, region_restriction_allowed, region_restriction_blocked, has_caption, definition, availability, class, thumbnail_default_sha256, thumbnail_medium_sha256, thumbnail_high_sha256, thumbnail_standard_sha256, thumbnail_maxres_sha256, thumbnail_default_missing_at, thumbnail_medium_missing_at, thumbnail_high_missing_at, thumbnail_standard_missing_at, thumbnail_maxres_missing_at, thumbnails_checked_at FROM youtube_videos
 WHERE video_id = $1
 `
 
@@ -91,6 +115,29 @@ func (q *Queries) GetYouTubeVideo(ctx context.Context, videoID string) (YoutubeV
 		&i.ThumbnailStandardUrl,
 		&i.ThumbnailMaxresUrl,
 		&i.PublishedAt,
+		&i.BroadcastState,
+		&i.Tags,
+		&i.CategoryID,
+		&i.DefaultAudioLanguage,
+		&i.PrivacyStatus,
+		&i.MadeForKids,
+		&i.RegionRestrictionAllowed,
+		&i.RegionRestrictionBlocked,
+		&i.HasCaption,
+		&i.Definition,
+		&i.Availability,
+		&i.Class,
+		&i.ThumbnailDefaultSha256,
+		&i.ThumbnailMediumSha256,
+		&i.ThumbnailHighSha256,
+		&i.ThumbnailStandardSha256,
+		&i.ThumbnailMaxresSha256,
+		&i.ThumbnailDefaultMissingAt,
+		&i.ThumbnailMediumMissingAt,
+		&i.ThumbnailHighMissingAt,
+		&i.ThumbnailStandardMissingAt,
+		&i.ThumbnailMaxresMissingAt,
+		&i.ThumbnailsCheckedAt,
 	)
 	return i, err
 }
@@ -112,13 +159,85 @@ func (q *Queries) GetYouTubeVideoLiveStreamingDetails(ctx context.Context, video
 	return i, err
 }
 
+const listLiveYouTubeVideoIDs = `-- name: ListLiveYouTubeVideoIDs :many
+SELECT video_id FROM youtube_video_live_streaming_details
+WHERE actual_end_time IS NULL
+  AND (actual_start_time >= $1::timestamptz
+    OR (actual_start_time IS NULL AND scheduled_start_time BETWEEN $1::timestamptz AND $2::timestamptz))
+ORDER BY scheduled_start_time, video_id
+`
+
+type ListLiveYouTubeVideoIDsParams struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Lists the broadcasts that started since `since` but have not ended,
+// and the upcoming ones scheduled to start in [since, until].
+func (q *Queries) ListLiveYouTubeVideoIDs(ctx context.Context, arg ListLiveYouTubeVideoIDsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listLiveYouTubeVideoIDs, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var video_id string
+		if err := rows.Scan(&video_id); err != nil {
+			return nil, err
+		}
+		items = append(items, video_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listYouTubeVideoIDsByChannel = `-- name: ListYouTubeVideoIDsByChannel :many
+SELECT video_id FROM youtube_videos
+WHERE video_id IN (
+    SELECT pv.video_id FROM youtube_playlist_videos pv
+    JOIN youtube_playlists p ON p.playlist_id = pv.playlist_id
+    WHERE p.channel_id = $1
+)
+ORDER BY video_id
+`
+
+func (q *Queries) ListYouTubeVideoIDsByChannel(ctx context.Context, channelID string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listYouTubeVideoIDsByChannel, channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var video_id string
+		if err := rows.Scan(&video_id); err != nil {
+			return nil, err
+		}
+		items = append(items, video_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listYouTubeVideos = `-- name: ListYouTubeVideos :many
-SELECT video_id, title, description, duration, thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url, published_at FROM youtube_videos
+SELECT video_id, title, description, duration, thumbnail_default_url, thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url, published_at, broadcast_state, tags, category_id, default_audio_language, privacy_status, made_for_kids, region_restriction_allowed, region_restriction_blocked, has_caption, definition, availability, class, thumbnail_default_sha256, thumbnail_medium_sha256, thumbnail_high_sha256, thumbnail_standard_sha256, thumbnail_maxres_sha256, thumbnail_default_missing_at, thumbnail_medium_missing_at, thumbnail_high_missing_at, thumbnail_standard_missing_at, thumbnail_maxres_missing_at, thumbnails_checked_at FROM youtube_videos
 WHERE video_id = ANY($1::text[])
+    AND ($2::text IS NULL OR class = $2::text)
 `
 
-func (q *Queries) ListYouTubeVideos(ctx context.Context, videoIds []string) ([]YoutubeVideo, error) {
-	rows, err := q.db.Query(ctx, listYouTubeVideos, videoIds)
+type ListYouTubeVideosParams struct {
+	VideoIds []string
+	Class    *string
+}
+
+// Lists the videos with the given IDs, only of the given class unless it is NULL.
+func (q *Queries) ListYouTubeVideos(ctx context.Context, arg ListYouTubeVideosParams) ([]YoutubeVideo, error) {
+	rows, err := q.db.Query(ctx, listYouTubeVideos, arg.VideoIds, arg.Class)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +256,29 @@ func (q *Queries) ListYouTubeVideos(ctx context.Context, videoIds []string) ([]Y
 			&i.ThumbnailStandardUrl,
 			&i.ThumbnailMaxresUrl,
 			&i.PublishedAt,
+			&i.BroadcastState,
+			&i.Tags,
+			&i.CategoryID,
+			&i.DefaultAudioLanguage,
+			&i.PrivacyStatus,
+			&i.MadeForKids,
+			&i.RegionRestrictionAllowed,
+			&i.RegionRestrictionBlocked,
+			&i.HasCaption,
+			&i.Definition,
+			&i.Availability,
+			&i.Class,
+			&i.ThumbnailDefaultSha256,
+			&i.ThumbnailMediumSha256,
+			&i.ThumbnailHighSha256,
+			&i.ThumbnailStandardSha256,
+			&i.ThumbnailMaxresSha256,
+			&i.ThumbnailDefaultMissingAt,
+			&i.ThumbnailMediumMissingAt,
+			&i.ThumbnailHighMissingAt,
+			&i.ThumbnailStandardMissingAt,
+			&i.ThumbnailMaxresMissingAt,
+			&i.ThumbnailsCheckedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -147,3 +289,192 @@ func (q *Queries) ListYouTubeVideos(ctx context.Context, videoIds []string) ([]Y
 	}
 	return items, nil
 }
+
+const updateYouTubeVideoAvailability = `-- name: UpdateYouTubeVideoAvailability :execrows
+UPDATE youtube_videos
+SET availability = $1::text
+WHERE video_id = $2::text
+    AND availability <> $1::text
+`
+
+type UpdateYouTubeVideoAvailabilityParams struct {
+	Availability string
+	VideoID      string
+}
+
+// Updates nothing if the video already has the availability.
+func (q *Queries) UpdateYouTubeVideoAvailability(ctx context.Context, arg UpdateYouTubeVideoAvailabilityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateYouTubeVideoAvailability, arg.Availability, arg.VideoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertYouTubeVideo = `-- name: UpsertYouTubeVideo :one
+INSERT INTO youtube_videos (
+    video_id, title, description, duration,
+    thumbnail_default_url,thumbnail_medium_url, thumbnail_high_url, thumbnail_standard_url, thumbnail_maxres_url,
+    published_at, broadcast_state,
+    tags, category_id, default_audio_language, privacy_status, made_for_kids,
+    region_restriction_allowed, region_restriction_blocked, has_caption, definition, class
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+ON CONFLICT (video_id) DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    duration = EXCLUDED.duration,
+    thumbnail_default_url = EXCLUDED.thumbnail_default_url,
+    thumbnail_medium_url = EXCLUDED.thumbnail_medium_url,
+    thumbnail_high_url = EXCLUDED.thumbnail_high_url,
+    thumbnail_standard_url = EXCLUDED.thumbnail_standard_url,
+    thumbnail_maxres_url = EXCLUDED.thumbnail_maxres_url,
+    thumbnail_default_sha256 = CASE WHEN youtube_videos.thumbnail_default_url IS DISTINCT FROM EXCLUDED.thumbnail_default_url THEN NULL ELSE youtube_videos.thumbnail_default_sha256 END,
+    thumbnail_medium_sha256 = CASE WHEN youtube_videos.thumbnail_medium_url IS DISTINCT FROM EXCLUDED.thumbnail_medium_url THEN NULL ELSE youtube_videos.thumbnail_medium_sha256 END,
+    thumbnail_high_sha256 = CASE WHEN youtube_videos.thumbnail_high_url IS DISTINCT FROM EXCLUDED.thumbnail_high_url THEN NULL ELSE youtube_videos.thumbnail_high_sha256 END,
+    thumbnail_standard_sha256 = CASE WHEN youtube_videos.thumbnail_standard_url IS DISTINCT FROM EXCLUDED.thumbnail_standard_url THEN NULL ELSE youtube_videos.thumbnail_standard_sha256 END,
+    thumbnail_maxres_sha256 = CASE WHEN youtube_videos.thumbnail_maxres_url IS DISTINCT FROM EXCLUDED.thumbnail_maxres_url THEN NULL ELSE youtube_videos.thumbnail_maxres_sha256 END,
+    thumbnail_default_missing_at = CASE WHEN youtube_videos.thumbnail_default_url IS DISTINCT FROM EXCLUDED.thumbnail_default_url THEN NULL ELSE youtube_videos.thumbnail_default_missing_at END,
+    thumbnail_medium_missing_at = CASE WHEN youtube_videos.thumbnail_medium_url IS DISTINCT FROM EXCLUDED.thumbnail_medium_url THEN NULL ELSE youtube_videos.thumbnail_medium_missing_at END,
+    thumbnail_high_missing_at = CASE WHEN youtube_videos.thumbnail_high_url IS DISTINCT FROM EXCLUDED.thumbnail_high_url THEN NULL ELSE youtube_videos.thumbnail_high_missing_at END,
+    thumbnail_standard_missing_at = CASE WHEN youtube_videos.thumbnail_standard_url IS DISTINCT FROM EXCLUDED.thumbnail_standard_url THEN NULL ELSE youtube_videos.thumbnail_standard_missing_at END,
+    thumbnail_maxres_missing_at = CASE WHEN youtube_videos.thumbnail_maxres_url IS DISTINCT FROM EXCLUDED.thumbnail_maxres_url THEN NULL ELSE youtube_videos.thumbnail_maxres_missing_at END,
+    published_at = EXCLUDED.published_at,
+    broadcast_state = CASE
+        WHEN youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed' THEN youtube_videos.broadcast_state
+        ELSE EXCLUDED.broadcast_state
+    END,
+    tags = EXCLUDED.tags,
+    category_id = EXCLUDED.category_id,
+    default_audio_language = EXCLUDED.default_audio_language,
+    privacy_status = EXCLUDED.privacy_status,
+    made_for_kids = EXCLUDED.made_for_kids,
+    region_restriction_allowed = EXCLUDED.region_restriction_allowed,
+    region_restriction_blocked = EXCLUDED.region_restriction_blocked,
+    has_caption = EXCLUDED.has_caption,
+    definition = EXCLUDED.definition,
+    class = CASE
+        WHEN youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed' THEN youtube_videos.class
+        WHEN youtube_videos.class = 'short' AND EXCLUDED.class = 'upload' THEN youtube_videos.class
+        ELSE EXCLUDED.class
+    END
+WHERE (
+    youtube_videos.title, youtube_videos.description, youtube_videos.duration,
+    youtube_videos.thumbnail_default_url, youtube_videos.thumbnail_medium_url, youtube_videos.thumbnail_high_url, youtube_videos.thumbnail_standard_url, youtube_videos.thumbnail_maxres_url,
+    youtube_videos.published_at,
+    youtube_videos.tags, youtube_videos.category_id, youtube_videos.default_audio_language, youtube_videos.privacy_status, youtube_videos.made_for_kids,
+    youtube_videos.region_restriction_allowed, youtube_videos.region_restriction_blocked, youtube_videos.has_caption, youtube_videos.definition
+) IS DISTINCT FROM (
+    EXCLUDED.title, EXCLUDED.description, EXCLUDED.duration,
+    EXCLUDED.thumbnail_default_url, EXCLUDED.thumbnail_medium_url, EXCLUDED.thumbnail_high_url, EXCLUDED.thumbnail_standard_url, EXCLUDED.thumbnail_maxres_url,
+    EXCLUDED.published_at,
+    EXCLUDED.tags, EXCLUDED.category_id, EXCLUDED.default_audio_language, EXCLUDED.privacy_status, EXCLUDED.made_for_kids,
+    EXCLUDED.region_restriction_allowed, EXCLUDED.region_restriction_blocked, EXCLUDED.has_caption, EXCLUDED.definition
+) OR (
+    youtube_videos.broadcast_state <> EXCLUDED.broadcast_state
+    AND NOT (youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed')
+) OR (
+    youtube_videos.class <> EXCLUDED.class
+    AND NOT (youtube_videos.broadcast_state = 'premiere' AND EXCLUDED.broadcast_state = 'completed')
+    AND NOT (youtube_videos.class = 'short' AND EXCLUDED.class = 'upload')
+)
+RETURNING (xmax = 0) AS inserted
+`
+
+type UpsertYouTubeVideoParams struct {
+	VideoID                  string
+	Title                    string
+	Description              string
+	Duration                 time.Duration
+	ThumbnailDefaultUrl      *string
+	ThumbnailMediumUrl       *string
+	ThumbnailHighUrl         *string
+	ThumbnailStandardUrl     *string
+	ThumbnailMaxresUrl       *string
+	PublishedAt              time.Time
+	BroadcastState           string
+	Tags                     []string
+	CategoryID               string
+	DefaultAudioLanguage     string
+	PrivacyStatus            string
+	MadeForKids              bool
+	RegionRestrictionAllowed []string
+	RegionRestrictionBlocked []string
+	HasCaption               bool
+	Definition               string
+	Class                    string
+}
+
+// A premiere is reported as a completed broadcast once it ends, so it is kept as a premiere, class included.
+// A short is kept as a short when it is saved as an upload, since only a full sync can tell shorts
+// that look like uploads apart, from the shorts playlist of the channel.
+// The hash of an archived thumbnail, and the record of it being removed, are cleared when its URL changes,
+// since they no longer apply.
+func (q *Queries) UpsertYouTubeVideo(ctx context.Context, arg UpsertYouTubeVideoParams) (bool, error) {
+	row := q.db.QueryRow(ctx, upsertYouTubeVideo,
+		arg.VideoID,
+		arg.Title,
+		arg.Description,
+		arg.Duration,
+		arg.ThumbnailDefaultUrl,
+		arg.ThumbnailMediumUrl,
+		arg.ThumbnailHighUrl,
+		arg.ThumbnailStandardUrl,
+		arg.ThumbnailMaxresUrl,
+		arg.PublishedAt,
+		arg.BroadcastState,
+		arg.Tags,
+		arg.CategoryID,
+		arg.DefaultAudioLanguage,
+		arg.PrivacyStatus,
+		arg.MadeForKids,
+		arg.RegionRestrictionAllowed,
+		arg.RegionRestrictionBlocked,
+		arg.HasCaption,
+		arg.Definition,
+		arg.Class,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
+
+const upsertYouTubeVideoLiveStreamingDetails = `-- name: UpsertYouTubeVideoLiveStreamingDetails :one
+INSERT INTO youtube_video_live_streaming_details (
+    video_id, actual_start_time, actual_end_time, scheduled_start_time
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (video_id) DO UPDATE
+SET actual_start_time = EXCLUDED.actual_start_time,
+    actual_end_time = EXCLUDED.actual_end_time,
+    scheduled_start_time = EXCLUDED.scheduled_start_time
+WHERE (
+    youtube_video_live_streaming_details.actual_start_time,
+    youtube_video_live_streaming_details.actual_end_time,
+    youtube_video_live_streaming_details.scheduled_start_time
+) IS DISTINCT FROM (
+    EXCLUDED.actual_start_time,
+    EXCLUDED.actual_end_time,
+    EXCLUDED.scheduled_start_time
+)
+RETURNING (xmax = 0) AS inserted
+`
+
+type UpsertYouTubeVideoLiveStreamingDetailsParams struct {
+	VideoID            string
+	ActualStartTime    *time.Time
+	ActualEndTime      *time.Time
+	ScheduledStartTime time.Time
+}
+
+func (q *Queries) UpsertYouTubeVideoLiveStreamingDetails(ctx context.Context, arg UpsertYouTubeVideoLiveStreamingDetailsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, upsertYouTubeVideoLiveStreamingDetails,
+		arg.VideoID,
+		arg.ActualStartTime,
+		arg.ActualEndTime,
+		arg.ScheduledStartTime,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"net/url"
+	"time"
+)
+
+// Snapshot captures the current profile and statistics of the channel.
+// It returns nil if the channel has no statistics.
+func (c *YouTubeChannel) Snapshot(capturedAt time.Time) *YouTubeChannelSnapshot {
+	if c.Statistics == nil {
+		return nil
+	}
+
+	return &YouTubeChannelSnapshot{
+		CapturedAt:  capturedAt,
+		Title:       c.Title,
+		Description: c.Description,
+		AvatarURL:   c.AvatarURL,
+		BannerURL:   c.BannerURL,
+		Statistics:  *c.Statistics,
+	}
+}
+
+// YouTubeChannelProfileField is a part of a channel profile that is compared between snapshots.
+type YouTubeChannelProfileField string
+
+const (
+	YouTubeChannelProfileFieldTitle       YouTubeChannelProfileField = "title"
+	YouTubeChannelProfileFieldDescription YouTubeChannelProfileField = "description"
+	YouTubeChannelProfileFieldAvatar      YouTubeChannelProfileField = "avatar"
+	YouTubeChannelProfileFieldBanner      YouTubeChannelProfileField = "banner"
+)
+
+// ProfileChanges returns the profile fields that differ from prev, such as a new avatar.
+// Statistics are not part of the profile.
+func (s *YouTubeChannelSnapshot) ProfileChanges(prev *YouTubeChannelSnapshot) []YouTubeChannelProfileField {
+	changes := make([]YouTubeChannelProfileField, 0)
+	if s.Title != prev.Title {
+		changes = append(changes, YouTubeChannelProfileFieldTitle)
+	}
+	if s.Description != prev.Description {
+		changes = append(changes, YouTubeChannelProfileFieldDescription)
+	}
+	if urlString(s.AvatarURL) != urlString(prev.AvatarURL) {
+		changes = append(changes, YouTubeChannelProfileFieldAvatar)
+	}
+	if urlString(s.BannerURL) != urlString(prev.BannerURL) {
+		changes = append(changes, YouTubeChannelProfileFieldBanner)
+	}
+	return changes
+}
+
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type iso8601Unit struct {
+	designator byte
+	duration   time.Duration
+}
+
+// Years and months have no fixed length, so they are converted with nominal lengths
+// of 365 and 30 days. YouTube itself only uses weeks, days, hours, minutes and seconds.
+var (
+	iso8601DateUnits = []iso8601Unit{
+		{'Y', 365 * 24 * time.Hour},
+		{'M', 30 * 24 * time.Hour},
+		{'W', 7 * 24 * time.Hour},
+		{'D', 24 * time.Hour},
+	}
+	iso8601TimeUnits = []iso8601Unit{
+		{'H', time.Hour},
+		{'M', time.Minute},
+		{'S', time.Second},
+	}
+)
+
+// ParseISO8601Duration parses an ISO 8601 duration such as "PT1H2M3S", "P1DT2H3M4S", "P2W" or "PT0.5S".
+// A leading sign is accepted, and the smallest component may have a decimal fraction.
+func ParseISO8601Duration(s string) (time.Duration, error) {
+	rest, negative := strings.CutPrefix(s, "-")
+	if !negative {
+		rest, _ = strings.CutPrefix(rest, "+")
+	}
+
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: missing P designator", s)
+	}
+
+	datePart, timePart, hasTime := strings.Cut(rest, "T")
+	if datePart == "" && timePart == "" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: no components", s)
+	}
+	if hasTime && timePart == "" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: no components after T designator", s)
+	}
+
+	dateDuration, dateFraction, err := parseISO8601Components(datePart, iso8601DateUnits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: %w", s, err)
+	}
+	if dateFraction && timePart != "" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: only the smallest component may have a fraction", s)
+	}
+
+	timeDuration, _, err := parseISO8601Components(timePart, iso8601TimeUnits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: %w", s, err)
+	}
+
+	if dateDuration > math.MaxInt64-timeDuration {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: out of range", s)
+	}
+	duration := dateDuration + timeDuration
+
+	if negative {
+		return -duration, nil
+	}
+
+	return duration, nil
+}
+
+// parseISO8601Components parses a sequence of number-designator pairs such as "1H2M3S".
+// The designators must appear in the order of units, each at most once.
+// It also reports whether the last component had a fraction.
+func parseISO8601Components(s string, units []iso8601Unit) (time.Duration, bool, error) {
+	var total time.Duration
+	next := 0
+	fraction := false
+
+	for s != "" {
+		if fraction {
+			return 0, false, fmt.Errorf("only the smallest component may have a fraction")
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if end <= 0 {
+			return 0, false, fmt.Errorf("expected a number at %q", s)
+		}
+		number, designator := s[:end], s[end]
+		s = s[end+1:]
+
+		i := next
+		for i < len(units) && units[i].designator != designator {
+			i++
+		}
+		if i == len(units) {
+			return 0, false, fmt.Errorf("unexpected designator %q", designator)
+		}
+		next = i + 1
+
+		value, hasFraction, err := parseISO8601Number(number, units[i].duration)
+		if err != nil {
+			return 0, false, err
+		}
+		fraction = hasFraction
+
+		if total > math.MaxInt64-value {
+			return 0, false, fmt.Errorf("out of range")
+		}
+		total += value
+	}
+
+	return total, fraction, nil
+}
+
+// parseISO8601Number converts a number such as "3" or "1.5" of the given unit into a duration.
+func parseISO8601Number(number string, unit time.Duration) (time.Duration, bool, error) {
+	integer, fraction, hasFraction := strings.Cut(strings.ReplaceAll(number, ",", "."), ".")
+	if integer == "" || (hasFraction && fraction == "") {
+		return 0, false, fmt.Errorf("invalid number %q", number)
+	}
+
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || n > int64(math.MaxInt64/unit) {
+		return 0, false, fmt.Errorf("number %q is out of range", number)
+	}
+	value := time.Duration(n) * unit
+
+	if hasFraction {
+		f, err := strconv.ParseFloat("0."+fraction, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid number %q", number)
+		}
+
+		frac := time.Duration(math.Round(f * float64(unit)))
+		if value > math.MaxInt64-frac {
+			return 0, false, fmt.Errorf("number %q is out of range", number)
+		}
+		value += frac
+	}
+
+	return value, hasFraction, nil
+}
+
+// FormatISO8601Duration formats d the way YouTube does, such as "PT1H2M3S" or "P1DT2H3M4S".
+// Sub-second precision is kept as a fraction of seconds, and zero is formatted as "P0D".
+func FormatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "P0D"
+	}
+
+	var b strings.Builder
+
+	// Use an unsigned value so that the minimum duration can be negated.
+	abs := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		abs = -abs
+	}
+
+	b.WriteByte('P')
+
+	const day = uint64(24 * time.Hour)
+	if days := abs / day; days > 0 {
+		b.WriteString(strconv.FormatUint(days, 10))
+		b.WriteByte('D')
+	}
+	abs %= day
+
+	if abs == 0 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+
+	if hours := abs / uint64(time.Hour); hours > 0 {
+		b.WriteString(strconv.FormatUint(hours, 10))
+		b.WriteByte('H')
+	}
+	abs %= uint64(time.Hour)
+
+	if minutes := abs / uint64(time.Minute); minutes > 0 {
+		b.WriteString(strconv.FormatUint(minutes, 10))
+		b.WriteByte('M')
+	}
+	abs %= uint64(time.Minute)
+
+	if abs > 0 {
+		b.WriteString(strconv.FormatUint(abs/uint64(time.Second), 10))
+		if nanos := abs % uint64(time.Second); nanos > 0 {
+			b.WriteByte('.')
+			b.WriteString(strings.TrimRight(fmt.Sprintf("%09d", nanos), "0"))
+		}
+		b.WriteByte('S')
+	}
+
+	return b.String()
+}
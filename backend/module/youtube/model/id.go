@@ -1,9 +1,30 @@
 package model
 
+import "strings"
+
 type YouTubeChannelID string
 
+// MembersOnlyPlaylistID returns the ID of the playlist that YouTube keeps of the channel's
+// members-only videos. It shares the suffix of the channel ID, like the uploads playlist.
+func (id YouTubeChannelID) MembersOnlyPlaylistID() YouTubePlaylistID {
+	return YouTubePlaylistID("UUMO" + strings.TrimPrefix(string(id), "UC"))
+}
+
+// ShortsPlaylistID returns the ID of the playlist that YouTube keeps of the channel's Shorts.
+func (id YouTubeChannelID) ShortsPlaylistID() YouTubePlaylistID {
+	return YouTubePlaylistID("UUSH" + strings.TrimPrefix(string(id), "UC"))
+}
+
 type YouTubeChannelHandle string
 
 type YouTubePlaylistID string
 
 type YouTubeVideoID string
+
+type YouTubeCommentID string
+
+type YouTubeCaptionID string
+
+type YouTubeLiveChatID string
+
+type YouTubeLiveChatMessageID string
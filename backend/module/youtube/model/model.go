@@ -2,6 +2,7 @@ package model
 
 import (
 	"net/url"
+	"slices"
 	"time"
 )
 
@@ -14,11 +15,54 @@ type YouTubeChannel struct {
 	YouTubeChannelIdentity
 
 	UploadsPlaylistID YouTubePlaylistID
+	Title             string
+	Description       string
+	Country           string // ISO 3166-1 alpha-2 code such as JP; empty if not set
+	AvatarURL         *url.URL
+	BannerURL         *url.URL
+	PublishedAt       time.Time
+	Statistics        *YouTubeChannelStatistics // nil unless fetched from the YouTube Data API
+}
+
+type YouTubeChannelStatistics struct {
+	SubscriberCount *int64 // nil if the channel hides it
+	ViewCount       int64
+	VideoCount      int64
+}
+
+// YouTubeChannelSnapshot is the profile and statistics of a channel at a point in time.
+type YouTubeChannelSnapshot struct {
+	CapturedAt  time.Time
+	Title       string
+	Description string
+	AvatarURL   *url.URL
+	BannerURL   *url.URL
+	Statistics  YouTubeChannelStatistics
 }
 
 type YouTubePlaylist struct {
-	ID    YouTubePlaylistID
-	Title string
+	ID            YouTubePlaylistID
+	Title         string
+	Description   string
+	Thumbnails    YouTubeThumbnails
+	ItemCount     int64
+	PrivacyStatus YouTubePrivacyStatus
+	PublishedAt   time.Time
+}
+
+// YouTubePlaylistItem is a video listed in a playlist.
+type YouTubePlaylistItem struct {
+	VideoID          YouTubeVideoID
+	Position         int64      // 0-based
+	AddedAt          time.Time  // when the video was added to the playlist
+	VideoPublishedAt *time.Time // nil if the video is private
+}
+
+// YouTubePlaylistMembership is the interval during which a video was in a playlist.
+type YouTubePlaylistMembership struct {
+	YouTubePlaylistItem
+
+	RemovedAt *time.Time // nil while the video is in the playlist
 }
 
 type YouTubeVideo struct {
@@ -26,12 +70,115 @@ type YouTubeVideo struct {
 	Title                string
 	Description          string
 	Duration             time.Duration
-	Thumbnails           YouTubeVideoThumbnails
+	Thumbnails           YouTubeThumbnails
+	BroadcastState       YouTubeBroadcastState
 	LiveStreamingDetails *YouTubeVideoLiveStreamingDetails // nil if not live streaming
 	PublishedAt          time.Time
+	Statistics           *YouTubeVideoStatistics // nil unless fetched from the YouTube Data API
+	Tags                 []string
+	CategoryID           string
+	DefaultAudioLanguage string // BCP-47 code such as ja; empty if not set
+	PrivacyStatus        YouTubePrivacyStatus
+	MadeForKids          bool
+	RegionRestriction    *YouTubeRegionRestriction // nil if available in every region
+	HasCaption           bool
+	Definition           YouTubeVideoDefinition
+	Availability         YouTubeVideoAvailability
+	Class                YouTubeVideoClass
+	ThumbnailHashes      YouTubeThumbnailHashes // only set when read from the database
+	ThumbnailsCheckedAt  *time.Time             // nil until the thumbnails are first archived; only set when read from the database
+}
+
+// YouTubeVideoClass tells what kind of content a video is, to separate Shorts from the rest.
+type YouTubeVideoClass string
+
+const (
+	YouTubeVideoClassShort       YouTubeVideoClass = "short"
+	YouTubeVideoClassUpload      YouTubeVideoClass = "upload"       // a regular upload
+	YouTubeVideoClassLiveArchive YouTubeVideoClass = "live_archive" // a live stream, including upcoming and ongoing ones
+	YouTubeVideoClassPremiere    YouTubeVideoClass = "premiere"
+)
+
+// YouTubeVideoAvailability tells whether and to whom a video can still be watched.
+type YouTubeVideoAvailability string
+
+const (
+	YouTubeVideoAvailabilityAvailable   YouTubeVideoAvailability = "available"
+	YouTubeVideoAvailabilityUnlisted    YouTubeVideoAvailability = "unlisted"
+	YouTubeVideoAvailabilityPrivate     YouTubeVideoAvailability = "private"
+	YouTubeVideoAvailabilityMembersOnly YouTubeVideoAvailability = "members_only"
+	YouTubeVideoAvailabilityDeleted     YouTubeVideoAvailability = "deleted"
+)
+
+// YouTubeVideoAvailabilityChange records when an availability of a video was first observed.
+type YouTubeVideoAvailabilityChange struct {
+	ObservedAt   time.Time
+	Availability YouTubeVideoAvailability
+}
+
+type YouTubePrivacyStatus string
+
+const (
+	YouTubePrivacyStatusPublic   YouTubePrivacyStatus = "public"
+	YouTubePrivacyStatusUnlisted YouTubePrivacyStatus = "unlisted"
+	YouTubePrivacyStatusPrivate  YouTubePrivacyStatus = "private"
+)
+
+type YouTubeVideoDefinition string
+
+const (
+	YouTubeVideoDefinitionHD YouTubeVideoDefinition = "hd"
+	YouTubeVideoDefinitionSD YouTubeVideoDefinition = "sd"
+)
+
+// YouTubeRegionRestriction lists the regions where a video is viewable or blocked,
+// as ISO 3166-1 alpha-2 codes. Only one of Allowed and Blocked is set.
+type YouTubeRegionRestriction struct {
+	Allowed []string // nil unless the video is viewable only in these regions
+	Blocked []string // nil unless the video is blocked in these regions
+}
+
+// IsBlockedIn reports whether the video cannot be viewed in the region.
+func (r *YouTubeRegionRestriction) IsBlockedIn(region string) bool {
+	if r == nil {
+		return false
+	}
+	if r.Allowed != nil {
+		return !slices.Contains(r.Allowed, region)
+	}
+	return slices.Contains(r.Blocked, region)
+}
+
+type YouTubeVideoStatistics struct {
+	ViewCount    int64
+	LikeCount    int64 // 0 if likes are hidden
+	CommentCount int64 // 0 if comments are disabled
+}
+
+// YouTubeVideoStatisticsSnapshot is the statistics of a video at a point in time.
+type YouTubeVideoStatisticsSnapshot struct {
+	CapturedAt time.Time
+	Statistics YouTubeVideoStatistics
+}
+
+// YouTubeVideoViewGain is the number of views a video gained over a period.
+type YouTubeVideoViewGain struct {
+	VideoID  YouTubeVideoID
+	ViewGain int64
 }
 
-type YouTubeVideoThumbnails struct {
+// YouTubeBroadcastState tells whether and how a video is (or was) broadcast live.
+type YouTubeBroadcastState string
+
+const (
+	YouTubeBroadcastStateNone      YouTubeBroadcastState = "none"      // a regular upload
+	YouTubeBroadcastStateUpcoming  YouTubeBroadcastState = "upcoming"  // a live stream that has not started yet
+	YouTubeBroadcastStateLive      YouTubeBroadcastState = "live"      // a live stream that is on air
+	YouTubeBroadcastStateCompleted YouTubeBroadcastState = "completed" // a live stream that has ended
+	YouTubeBroadcastStatePremiere  YouTubeBroadcastState = "premiere"  // a premiere; its live streaming details tell whether it has started or ended
+)
+
+type YouTubeThumbnails struct {
 	Default  *url.URL
 	Medium   *url.URL
 	High     *url.URL
@@ -40,7 +187,127 @@ type YouTubeVideoThumbnails struct {
 }
 
 type YouTubeVideoLiveStreamingDetails struct {
-	ActualStartTime time.Time
-	ActualEndTime   time.Time
+	ActualStartTime *time.Time // nil until the broadcast starts
+	ActualEndTime   *time.Time // nil until the broadcast ends
 	ScheduledStart  time.Time
+
+	// ActiveLiveChatID is the live chat of the broadcast, set only while the chat is open
+	// and only when fetched from the API, since it is not stored.
+	ActiveLiveChatID *YouTubeLiveChatID
+}
+
+type YouTubeComment struct {
+	ID                YouTubeCommentID
+	VideoID           YouTubeVideoID
+	ParentID          *YouTubeCommentID // nil for top-level comments
+	AuthorDisplayName string
+	AuthorChannelID   YouTubeChannelID // empty if unknown
+	Text              string
+	LikeCount         int64
+	ReplyCount        int64 // 0 for replies
+	PublishedAt       time.Time
+	UpdatedAt         time.Time
+}
+
+// YouTubeCommentThread is a top-level comment and the replies to it.
+type YouTubeCommentThread struct {
+	TopLevelComment *YouTubeComment
+	// Replies holds at most a few replies as returned with the thread;
+	// the rest have to be listed separately if there are more than ReplyCount of TopLevelComment.
+	Replies []*YouTubeComment
+}
+
+// YouTubeCommentSync records how far the comments of a video have been synced.
+type YouTubeCommentSync struct {
+	SyncedAt    time.Time // when the comment threads were last listed
+	RefreshedAt time.Time // when every comment thread was last listed, not just the new ones
+}
+
+// YouTubeCaptionTrack is a caption track of a video, without its text.
+type YouTubeCaptionTrack struct {
+	ID        YouTubeCaptionID
+	VideoID   YouTubeVideoID
+	Language  string // BCP-47 language tag
+	Name      string
+	TrackKind YouTubeCaptionTrackKind
+	UpdatedAt time.Time
+}
+
+type YouTubeCaptionTrackKind string
+
+const (
+	YouTubeCaptionTrackKindStandard YouTubeCaptionTrackKind = "standard"
+	YouTubeCaptionTrackKindASR      YouTubeCaptionTrackKind = "asr" // generated by automatic speech recognition
+	YouTubeCaptionTrackKindForced   YouTubeCaptionTrackKind = "forced"
+)
+
+// YouTubeCaptionSegment is a piece of caption text shown from Start to End of the video.
+type YouTubeCaptionSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// YouTubeLiveChatMessage is a message or an event, such as a Super Chat or a new membership,
+// posted to the live chat of a broadcast.
+type YouTubeLiveChatMessage struct {
+	ID          YouTubeLiveChatMessageID
+	Type        YouTubeLiveChatMessageType
+	Author      YouTubeLiveChatAuthor
+	Text        string // empty if the author wrote nothing, as with most membership events
+	PublishedAt time.Time
+	SuperChat   *YouTubeSuperChat       // nil unless Type is super_chat or super_sticker
+	Membership  *YouTubeMembershipEvent // nil unless Type is a membership event
+}
+
+type YouTubeLiveChatMessageType string
+
+const (
+	YouTubeLiveChatMessageTypeText                   YouTubeLiveChatMessageType = "text"
+	YouTubeLiveChatMessageTypeSuperChat              YouTubeLiveChatMessageType = "super_chat"
+	YouTubeLiveChatMessageTypeSuperSticker           YouTubeLiveChatMessageType = "super_sticker"
+	YouTubeLiveChatMessageTypeNewMember              YouTubeLiveChatMessageType = "new_member"
+	YouTubeLiveChatMessageTypeMemberMilestone        YouTubeLiveChatMessageType = "member_milestone"
+	YouTubeLiveChatMessageTypeMembershipGifting      YouTubeLiveChatMessageType = "membership_gifting"
+	YouTubeLiveChatMessageTypeGiftMembershipReceived YouTubeLiveChatMessageType = "gift_membership_received"
+)
+
+type YouTubeLiveChatAuthor struct {
+	ChannelID   YouTubeChannelID
+	DisplayName string
+	IsOwner     bool
+	IsModerator bool
+	IsMember    bool
+}
+
+// YouTubeSuperChat is the payment of a Super Chat or a Super Sticker.
+type YouTubeSuperChat struct {
+	AmountMicros  int64  // in millionths of the currency unit
+	Currency      string // ISO 4217 currency code
+	AmountDisplay string // such as "¥1,000"
+	Tier          int64
+}
+
+// YouTubeMembershipEvent is the membership part of a new member, member milestone
+// or membership gifting message.
+type YouTubeMembershipEvent struct {
+	LevelName string
+	Months    int64 // the months of membership celebrated by a member milestone; 0 otherwise
+	GiftCount int64 // the memberships given by a membership gifting; 0 otherwise
+}
+
+// YouTubeFeedEntry is a video in the Atom feed of a channel, which lists its latest
+// uploads and scheduled broadcasts without spending any API quota.
+type YouTubeFeedEntry struct {
+	VideoID     YouTubeVideoID
+	ChannelID   YouTubeChannelID
+	Title       string
+	PublishedAt time.Time
+	UpdatedAt   time.Time
+}
+
+// YouTubeFeedNotification is a change to the feed of a channel pushed by the WebSub hub.
+type YouTubeFeedNotification struct {
+	Entries         []*YouTubeFeedEntry // videos uploaded, scheduled or updated
+	DeletedVideoIDs []YouTubeVideoID    // videos deleted or made private
 }
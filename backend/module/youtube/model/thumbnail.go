@@ -0,0 +1,60 @@
+package model
+
+import (
+	"net/url"
+	"time"
+)
+
+type YouTubeThumbnailSize string
+
+const (
+	YouTubeThumbnailSizeDefault  YouTubeThumbnailSize = "default"
+	YouTubeThumbnailSizeMedium   YouTubeThumbnailSize = "medium"
+	YouTubeThumbnailSizeHigh     YouTubeThumbnailSize = "high"
+	YouTubeThumbnailSizeStandard YouTubeThumbnailSize = "standard"
+	YouTubeThumbnailSizeMaxres   YouTubeThumbnailSize = "maxres"
+)
+
+// YouTubeThumbnailSizes lists every thumbnail size, smallest first.
+var YouTubeThumbnailSizes = []YouTubeThumbnailSize{
+	YouTubeThumbnailSizeDefault,
+	YouTubeThumbnailSizeMedium,
+	YouTubeThumbnailSizeHigh,
+	YouTubeThumbnailSizeStandard,
+	YouTubeThumbnailSizeMaxres,
+}
+
+// URL returns the URL of the thumbnail of the size, or nil if there is none.
+func (t *YouTubeThumbnails) URL(size YouTubeThumbnailSize) *url.URL {
+	switch size {
+	case YouTubeThumbnailSizeDefault:
+		return t.Default
+	case YouTubeThumbnailSizeMedium:
+		return t.Medium
+	case YouTubeThumbnailSizeHigh:
+		return t.High
+	case YouTubeThumbnailSizeStandard:
+		return t.Standard
+	case YouTubeThumbnailSizeMaxres:
+		return t.Maxres
+	}
+	return nil
+}
+
+// YouTubeThumbnailHashes are the hex SHA-256 hashes of the archived images of YouTubeThumbnails,
+// each empty until the image at the current URL of the size is archived.
+type YouTubeThumbnailHashes struct {
+	Default  string
+	Medium   string
+	High     string
+	Standard string
+	Maxres   string
+}
+
+// YouTubeThumbnailArchive is an image of a thumbnail that has been saved to the blob store.
+type YouTubeThumbnailArchive struct {
+	Size       YouTubeThumbnailSize
+	SHA256     string // hex
+	URL        *url.URL
+	ArchivedAt time.Time // when the image was first archived
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// YouTubeShortsMaxDuration is the longest a Short can be.
+const YouTubeShortsMaxDuration = 3 * time.Minute
+
+// ClassifyYouTubeVideo classifies a video from its broadcast state and duration,
+// and whether its player is vertical, which is how Shorts are shot.
+// Videos in the channel's Shorts playlist should be classified as Shorts regardless.
+func ClassifyYouTubeVideo(video *YouTubeVideo, vertical bool) YouTubeVideoClass {
+	switch video.BroadcastState {
+	case YouTubeBroadcastStatePremiere:
+		return YouTubeVideoClassPremiere
+	case YouTubeBroadcastStateUpcoming, YouTubeBroadcastStateLive, YouTubeBroadcastStateCompleted:
+		return YouTubeVideoClassLiveArchive
+	}
+
+	if vertical && video.Duration > 0 && video.Duration <= YouTubeShortsMaxDuration {
+		return YouTubeVideoClassShort
+	}
+
+	return YouTubeVideoClassUpload
+}
@@ -0,0 +1,24 @@
+package model
+
+// YouTubeViewMilestones are the view counts worth celebrating, in ascending order.
+var YouTubeViewMilestones = []int64{
+	10_000,
+	100_000,
+	500_000,
+	1_000_000,
+	5_000_000,
+	10_000_000,
+	100_000_000,
+}
+
+// CrossedViewMilestone returns the highest milestone in YouTubeViewMilestones that a video
+// passed while its views went from prev to cur, or false if it passed none.
+func CrossedViewMilestone(prev int64, cur int64) (int64, bool) {
+	for i := len(YouTubeViewMilestones) - 1; i >= 0; i-- {
+		milestone := YouTubeViewMilestones[i]
+		if prev < milestone && milestone <= cur {
+			return milestone, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,176 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"google.golang.org/api/googleapi"
+)
+
+// ----- In-memory cache -----
+
+var _ repository.YouTubeResponseCache = &youtubeMemoryResponseCache{}
+
+// youtubeMemoryResponseCache keeps responses for the lifetime of the process.
+type youtubeMemoryResponseCache struct {
+	mu        sync.RWMutex
+	responses map[string]*repository.YouTubeCachedResponse
+}
+
+func NewYouTubeMemoryResponseCache() repository.YouTubeResponseCache {
+	return &youtubeMemoryResponseCache{
+		responses: make(map[string]*repository.YouTubeCachedResponse),
+	}
+}
+
+func (c *youtubeMemoryResponseCache) Get(ctx context.Context, key string) (*repository.YouTubeCachedResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.responses[key], nil
+}
+
+func (c *youtubeMemoryResponseCache) Put(ctx context.Context, key string, response *repository.YouTubeCachedResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.responses[key] = response
+
+	return nil
+}
+
+// ----- Database cache -----
+
+var _ repository.YouTubeResponseCache = &youtubeDBResponseCache{}
+
+// youtubeDBResponseCache keeps responses in the database, so that they outlive the process.
+type youtubeDBResponseCache struct {
+	cacheStore repository.YouTubeAPIResponseCacheStore
+}
+
+func NewYouTubeDBResponseCache(cacheStore repository.YouTubeAPIResponseCacheStore) repository.YouTubeResponseCache {
+	return &youtubeDBResponseCache{
+		cacheStore: cacheStore,
+	}
+}
+
+func (c *youtubeDBResponseCache) Get(ctx context.Context, key string) (*repository.YouTubeCachedResponse, error) {
+	response, err := c.cacheStore.GetAPIResponseCache(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *youtubeDBResponseCache) Put(ctx context.Context, key string, response *repository.YouTubeCachedResponse) error {
+	return c.cacheStore.PutAPIResponseCache(ctx, key, response)
+}
+
+// ----- Conditional requests -----
+
+// WithResponseCache makes the repository send the ETag of a cached response with each request
+// and serve the cached response when the API replies that it has not been modified.
+func WithResponseCache(cache repository.YouTubeResponseCache) YouTubeRepositoryOption {
+	return func(r *youtubeRepository) {
+		r.responseCache = cache
+	}
+}
+
+// cacheKey identifies a request by its endpoint and parameters.
+func cacheKey(endpoint string, params url.Values) string {
+	return endpoint + "?" + params.Encode()
+}
+
+// withParams makes do send params as its query parameters, in addition to opts.
+func withParams[R any](params url.Values, do func(opts ...googleapi.CallOption) (R, error)) func(opts ...googleapi.CallOption) (R, error) {
+	paramOpts := make([]googleapi.CallOption, 0, len(params))
+	for key, values := range params {
+		paramOpts = append(paramOpts, googleapi.QueryParameter(key, values...))
+	}
+
+	return func(opts ...googleapi.CallOption) (R, error) {
+		return do(slices.Concat(paramOpts, opts)...)
+	}
+}
+
+// doCachedCall is doCall with conditional requests. If a response to the same request is cached,
+// its ETag is set with ifNoneMatch, and the cached response is returned on 304 Not Modified.
+// params are sent with the request and, with the endpoint, make the key of its cached response,
+// so every parameter of the request must be passed in params rather than set on the call.
+func doCachedCall[R any, C any](
+	ctx context.Context,
+	r *youtubeRepository,
+	endpoint string,
+	params url.Values,
+	ifNoneMatch func(etag string) C,
+	do func(opts ...googleapi.CallOption) (*R, error),
+) (*R, error) {
+	do = withParams(params, do)
+
+	if r.responseCache == nil {
+		return doCall(ctx, r, endpoint, do)
+	}
+
+	key := cacheKey(endpoint, params)
+
+	cached, err := r.responseCache.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached response: %w", err)
+	}
+	if cached != nil {
+		ifNoneMatch(cached.ETag)
+	}
+
+	response, err := doCall(ctx, r, endpoint, do)
+	if cached != nil && isNotModified(err) {
+		var cachedResponse R
+		if err := json.Unmarshal(cached.Body, &cachedResponse); err != nil {
+			return nil, fmt.Errorf("failed to decode cached response: %w", err)
+		}
+		return &cachedResponse, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	// Every list response carries its ETag in the body.
+	var etag struct {
+		ETag string `json:"etag"`
+	}
+	if err := json.Unmarshal(body, &etag); err != nil {
+		return nil, fmt.Errorf("failed to decode response ETag: %w", err)
+	}
+
+	if etag.ETag != "" {
+		err := r.responseCache.Put(ctx, key, &repository.YouTubeCachedResponse{
+			ETag: etag.ETag,
+			Body: body,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cache response: %w", err)
+		}
+	}
+
+	return response, nil
+}
+
+func isNotModified(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotModified
+}
@@ -0,0 +1,163 @@
+package adapter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+)
+
+// captionFormat is the format captions are downloaded in.
+const captionFormat = "vtt"
+
+// captionTagPattern matches the markup inside cue text, such as <c>, <i>, <font color="..."> and
+// the word timestamps like <00:00:01.500> of automatically generated captions.
+var captionTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// parseCaption parses a caption file in WebVTT ("vtt") or SubRip ("srt") format into segments.
+// Cues without text are dropped, and markup and line breaks inside cues are removed.
+func parseCaption(format string, data []byte) ([]*model.YouTubeCaptionSegment, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	blocks := splitCaptionBlocks(data)
+
+	switch format {
+	case "vtt":
+		if len(blocks) == 0 || !strings.HasPrefix(blocks[0][0], "WEBVTT") {
+			return nil, fmt.Errorf("missing WEBVTT header")
+		}
+		return parseCaptionCues(blocks[1:], parseVTTTimestamp)
+	case "srt":
+		return parseCaptionCues(blocks, parseSRTTimestamp)
+	default:
+		return nil, fmt.Errorf("unknown caption format: %s", format)
+	}
+}
+
+// splitCaptionBlocks splits data into blocks of lines separated by blank lines.
+func splitCaptionBlocks(data []byte) [][]string {
+	var blocks [][]string
+	var block []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" {
+			if len(block) > 0 {
+				blocks = append(blocks, block)
+				block = nil
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+// parseCaptionCues parses blocks made of an optional identifier line, a timing line and text lines.
+// Blocks without a timing line, such as the NOTE, STYLE and REGION blocks of WebVTT, are skipped.
+func parseCaptionCues(blocks [][]string, parseTimestamp func(string) (time.Duration, error)) ([]*model.YouTubeCaptionSegment, error) {
+	segments := make([]*model.YouTubeCaptionSegment, 0, len(blocks))
+	for _, block := range blocks {
+		timing := slices.IndexFunc(block, func(line string) bool { return strings.Contains(line, "-->") })
+		if timing < 0 || timing > 1 {
+			continue
+		}
+
+		start, end, err := parseCaptionTiming(block[timing], parseTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timing %q: %w", block[timing], err)
+		}
+
+		lines := make([]string, 0, len(block)-timing-1)
+		for _, line := range block[timing+1:] {
+			line = strings.TrimSpace(html.UnescapeString(captionTagPattern.ReplaceAllString(line, "")))
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		segments = append(segments, &model.YouTubeCaptionSegment{
+			Start: start,
+			End:   end,
+			Text:  strings.Join(lines, " "),
+		})
+	}
+
+	return segments, nil
+}
+
+// parseCaptionTiming parses a line like "00:00:01.000 --> 00:00:04.000 align:start position:0%".
+func parseCaptionTiming(line string, parseTimestamp func(string) (time.Duration, error)) (time.Duration, time.Duration, error) {
+	startField, rest, _ := strings.Cut(line, "-->")
+	endFields := strings.Fields(rest)
+	if len(endFields) == 0 {
+		return 0, 0, fmt.Errorf("missing end timestamp")
+	}
+
+	start, err := parseTimestamp(strings.TrimSpace(startField))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse start timestamp: %w", err)
+	}
+
+	end, err := parseTimestamp(endFields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse end timestamp: %w", err)
+	}
+
+	return start, end, nil
+}
+
+// parseVTTTimestamp parses "hh:mm:ss.ttt" or "mm:ss.ttt". Hours may have more than two digits.
+func parseVTTTimestamp(s string) (time.Duration, error) {
+	return parseCaptionTimestamp(s, ".")
+}
+
+// parseSRTTimestamp parses "hh:mm:ss,ttt".
+func parseSRTTimestamp(s string) (time.Duration, error) {
+	return parseCaptionTimestamp(s, ",")
+}
+
+func parseCaptionTimestamp(s string, fractionSeparator string) (time.Duration, error) {
+	clock, fraction, ok := strings.Cut(s, fractionSeparator)
+	if !ok || len(fraction) != 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+
+	millis, err := strconv.Atoi(fraction)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+
+	fields := strings.Split(clock, ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+
+	var d time.Duration
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp: %s", s)
+		}
+		d = d*60 + time.Duration(n)*time.Second
+	}
+
+	return d + time.Duration(millis)*time.Millisecond, nil
+}
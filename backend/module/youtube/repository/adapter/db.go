@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/tocoteron/omigoto/backend/gen/db"
@@ -14,16 +15,47 @@ import (
 
 var _ repository.YouTubeDBRepository = &youtubeDBRepository{}
 
+// DBTX is a database handle that can both run queries and begin transactions,
+// such as *pgxpool.Pool, *pgx.Conn or pgx.Tx (which begins savepoints).
+type DBTX interface {
+	db.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type youtubeDBRepository struct {
-	q db.Querier
+	conn DBTX
+	q    *db.Queries
 }
 
-func NewYouTubeDBRepository(q db.Querier) repository.YouTubeDBRepository {
+func NewYouTubeDBRepository(conn DBTX) repository.YouTubeDBRepository {
+	return &youtubeDBRepository{
+		conn: conn,
+		q:    db.New(conn),
+	}
+}
+
+// ----- Transaction operations -----
+
+// WithTx returns a repository that runs all of its queries in tx.
+func (r *youtubeDBRepository) WithTx(tx pgx.Tx) *youtubeDBRepository {
 	return &youtubeDBRepository{
-		q: q,
+		conn: tx,
+		q:    r.q.WithTx(tx),
 	}
 }
 
+// RunInTx calls fn with a repository bound to a new transaction, committing it if fn
+// returns nil and rolling it back otherwise. Deferred constraints are checked on commit.
+func (r *youtubeDBRepository) RunInTx(ctx context.Context, fn func(repo repository.YouTubeDBRepository) error) error {
+	err := pgx.BeginFunc(ctx, r.conn, func(tx pgx.Tx) error {
+		return fn(r.WithTx(tx))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run in transaction: %w", err)
+	}
+	return nil
+}
+
 // ----- Channel operations -----
 
 func (r *youtubeDBRepository) CreateChannel(ctx context.Context, channel *model.YouTubeChannel) error {
@@ -31,6 +63,12 @@ func (r *youtubeDBRepository) CreateChannel(ctx context.Context, channel *model.
 		ChannelID:         string(channel.ID),
 		Handle:            string(channel.Handle),
 		UploadsPlaylistID: string(channel.UploadsPlaylistID),
+		Title:             channel.Title,
+		Description:       channel.Description,
+		Country:           channel.Country,
+		AvatarUrl:         urlToString(channel.AvatarURL),
+		BannerUrl:         urlToString(channel.BannerURL),
+		PublishedAt:       channel.PublishedAt,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create channel: %w", err)
@@ -38,43 +76,124 @@ func (r *youtubeDBRepository) CreateChannel(ctx context.Context, channel *model.
 	return nil
 }
 
+func (r *youtubeDBRepository) UpsertChannel(ctx context.Context, channel *model.YouTubeChannel) (repository.UpsertResult, error) {
+	result, err := upsertResult(r.q.UpsertYouTubeChannel(ctx, db.UpsertYouTubeChannelParams{
+		ChannelID:         string(channel.ID),
+		Handle:            string(channel.Handle),
+		UploadsPlaylistID: string(channel.UploadsPlaylistID),
+		Title:             channel.Title,
+		Description:       channel.Description,
+		Country:           channel.Country,
+		AvatarUrl:         urlToString(channel.AvatarURL),
+		BannerUrl:         urlToString(channel.BannerURL),
+		PublishedAt:       channel.PublishedAt,
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert channel: %w", err)
+	}
+	return result, nil
+}
+
 func (r *youtubeDBRepository) GetChannel(ctx context.Context, channelID model.YouTubeChannelID) (*model.YouTubeChannel, error) {
 	dbChannel, err := r.q.GetYouTubeChannel(ctx, string(channelID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get channel: %w", err)
+		return nil, fmt.Errorf("failed to get channel: %w", dbError(err))
 	}
 
-	return &model.YouTubeChannel{
-		YouTubeChannelIdentity: model.YouTubeChannelIdentity{
-			ID:     model.YouTubeChannelID(dbChannel.ChannelID),
-			Handle: model.YouTubeChannelHandle(dbChannel.Handle),
-		},
-		UploadsPlaylistID: model.YouTubePlaylistID(dbChannel.UploadsPlaylistID),
-	}, nil
+	channel, err := convertYouTubeChannel(dbChannel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert channel: %w", err)
+	}
+
+	return channel, nil
 }
 
 func (r *youtubeDBRepository) GetChannelByHandle(ctx context.Context, handle model.YouTubeChannelHandle) (*model.YouTubeChannel, error) {
 	dbChannel, err := r.q.GetYouTubeChannelByHandle(ctx, string(handle))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get channel by handle: %w", err)
+		return nil, fmt.Errorf("failed to get channel by handle: %w", dbError(err))
 	}
 
-	return &model.YouTubeChannel{
-		YouTubeChannelIdentity: model.YouTubeChannelIdentity{
-			ID:     model.YouTubeChannelID(dbChannel.ChannelID),
-			Handle: model.YouTubeChannelHandle(dbChannel.Handle),
-		},
-		UploadsPlaylistID: model.YouTubePlaylistID(dbChannel.UploadsPlaylistID),
-	}, nil
+	channel, err := convertYouTubeChannel(dbChannel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// ----- Channel snapshot operations -----
+
+func (r *youtubeDBRepository) CreateChannelSnapshot(ctx context.Context, channelID model.YouTubeChannelID, snapshot *model.YouTubeChannelSnapshot) error {
+	err := r.q.CreateYouTubeChannelSnapshot(ctx, db.CreateYouTubeChannelSnapshotParams{
+		ChannelID:       string(channelID),
+		CapturedAt:      snapshot.CapturedAt,
+		Title:           snapshot.Title,
+		Description:     snapshot.Description,
+		AvatarUrl:       urlToString(snapshot.AvatarURL),
+		BannerUrl:       urlToString(snapshot.BannerURL),
+		SubscriberCount: snapshot.Statistics.SubscriberCount,
+		ViewCount:       snapshot.Statistics.ViewCount,
+		VideoCount:      snapshot.Statistics.VideoCount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel snapshot: %w", err)
+	}
+	return nil
+}
+
+func (r *youtubeDBRepository) GetLatestChannelSnapshot(ctx context.Context, channelID model.YouTubeChannelID) (*model.YouTubeChannelSnapshot, error) {
+	dbSnapshot, err := r.q.GetLatestYouTubeChannelSnapshot(ctx, string(channelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest channel snapshot: %w", dbError(err))
+	}
+
+	snapshot, err := convertYouTubeChannelSnapshot(dbSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert channel snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+func (r *youtubeDBRepository) ListChannelSnapshots(ctx context.Context, channelID model.YouTubeChannelID, since time.Time, until time.Time) ([]*model.YouTubeChannelSnapshot, error) {
+	dbSnapshots, err := r.q.ListYouTubeChannelSnapshots(ctx, db.ListYouTubeChannelSnapshotsParams{
+		ChannelID: string(channelID),
+		Since:     since,
+		Until:     until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel snapshots: %w", err)
+	}
+
+	snapshots := make([]*model.YouTubeChannelSnapshot, len(dbSnapshots))
+	for i, dbSnapshot := range dbSnapshots {
+		snapshot, err := convertYouTubeChannelSnapshot(dbSnapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert channel snapshot: %w", err)
+		}
+		snapshots[i] = snapshot
+	}
+
+	return snapshots, nil
 }
 
 // ----- Playlist operations -----
 
 func (r *youtubeDBRepository) CreatePlaylist(ctx context.Context, channelID model.YouTubeChannelID, playlist *model.YouTubePlaylist) error {
 	err := r.q.CreateYouTubePlaylist(ctx, db.CreateYouTubePlaylistParams{
-		PlaylistID: string(playlist.ID),
-		ChannelID:  string(channelID),
-		Title:      playlist.Title,
+		PlaylistID:           string(playlist.ID),
+		ChannelID:            string(channelID),
+		Title:                playlist.Title,
+		Description:          playlist.Description,
+		ThumbnailDefaultUrl:  urlToString(playlist.Thumbnails.Default),
+		ThumbnailMediumUrl:   urlToString(playlist.Thumbnails.Medium),
+		ThumbnailHighUrl:     urlToString(playlist.Thumbnails.High),
+		ThumbnailStandardUrl: urlToString(playlist.Thumbnails.Standard),
+		ThumbnailMaxresUrl:   urlToString(playlist.Thumbnails.Maxres),
+		ItemCount:            playlist.ItemCount,
+		PrivacyStatus:        string(playlist.PrivacyStatus),
+		PublishedAt:          playlist.PublishedAt,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create playlist: %w", err)
@@ -82,16 +201,39 @@ func (r *youtubeDBRepository) CreatePlaylist(ctx context.Context, channelID mode
 	return nil
 }
 
+func (r *youtubeDBRepository) UpsertPlaylist(ctx context.Context, channelID model.YouTubeChannelID, playlist *model.YouTubePlaylist) (repository.UpsertResult, error) {
+	result, err := upsertResult(r.q.UpsertYouTubePlaylist(ctx, db.UpsertYouTubePlaylistParams{
+		PlaylistID:           string(playlist.ID),
+		ChannelID:            string(channelID),
+		Title:                playlist.Title,
+		Description:          playlist.Description,
+		ThumbnailDefaultUrl:  urlToString(playlist.Thumbnails.Default),
+		ThumbnailMediumUrl:   urlToString(playlist.Thumbnails.Medium),
+		ThumbnailHighUrl:     urlToString(playlist.Thumbnails.High),
+		ThumbnailStandardUrl: urlToString(playlist.Thumbnails.Standard),
+		ThumbnailMaxresUrl:   urlToString(playlist.Thumbnails.Maxres),
+		ItemCount:            playlist.ItemCount,
+		PrivacyStatus:        string(playlist.PrivacyStatus),
+		PublishedAt:          playlist.PublishedAt,
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert playlist: %w", err)
+	}
+	return result, nil
+}
+
 func (r *youtubeDBRepository) GetPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID) (*model.YouTubePlaylist, error) {
 	dbPlaylist, err := r.q.GetYouTubePlaylist(ctx, string(playlistID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get playlist: %w", err)
+		return nil, fmt.Errorf("failed to get playlist: %w", dbError(err))
 	}
 
-	return &model.YouTubePlaylist{
-		ID:    model.YouTubePlaylistID(dbPlaylist.PlaylistID),
-		Title: dbPlaylist.Title,
-	}, nil
+	playlist, err := convertYouTubePlaylist(dbPlaylist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert playlist: %w", err)
+	}
+
+	return playlist, nil
 }
 
 func (r *youtubeDBRepository) ListPlaylists(ctx context.Context, playlistIDs []model.YouTubePlaylistID) ([]*model.YouTubePlaylist, error) {
@@ -107,10 +249,11 @@ func (r *youtubeDBRepository) ListPlaylists(ctx context.Context, playlistIDs []m
 
 	playlists := make([]*model.YouTubePlaylist, len(dbPlaylists))
 	for i, dbPlaylist := range dbPlaylists {
-		playlists[i] = &model.YouTubePlaylist{
-			ID:    model.YouTubePlaylistID(dbPlaylist.PlaylistID),
-			Title: dbPlaylist.Title,
+		playlist, err := convertYouTubePlaylist(dbPlaylist)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert playlist: %w", err)
 		}
+		playlists[i] = playlist
 	}
 
 	return playlists, nil
@@ -134,16 +277,27 @@ func (r *youtubeDBRepository) ListPlaylistIDsByChannel(ctx context.Context, chan
 
 func (r *youtubeDBRepository) CreateVideo(ctx context.Context, video *model.YouTubeVideo) error {
 	err := r.q.CreateYouTubeVideo(ctx, db.CreateYouTubeVideoParams{
-		VideoID:              string(video.ID),
-		Title:                video.Title,
-		Description:          video.Description,
-		Duration:             video.Duration,
-		ThumbnailDefaultUrl:  urlToString(video.Thumbnails.Default),
-		ThumbnailMediumUrl:   urlToString(video.Thumbnails.Medium),
-		ThumbnailHighUrl:     urlToString(video.Thumbnails.High),
-		ThumbnailStandardUrl: urlToString(video.Thumbnails.Standard),
-		ThumbnailMaxresUrl:   urlToString(video.Thumbnails.Maxres),
-		PublishedAt:          video.PublishedAt,
+		VideoID:                  string(video.ID),
+		Title:                    video.Title,
+		Description:              video.Description,
+		Duration:                 video.Duration,
+		ThumbnailDefaultUrl:      urlToString(video.Thumbnails.Default),
+		ThumbnailMediumUrl:       urlToString(video.Thumbnails.Medium),
+		ThumbnailHighUrl:         urlToString(video.Thumbnails.High),
+		ThumbnailStandardUrl:     urlToString(video.Thumbnails.Standard),
+		ThumbnailMaxresUrl:       urlToString(video.Thumbnails.Maxres),
+		PublishedAt:              video.PublishedAt,
+		BroadcastState:           string(video.BroadcastState),
+		Tags:                     nonNilStrings(video.Tags),
+		CategoryID:               video.CategoryID,
+		DefaultAudioLanguage:     video.DefaultAudioLanguage,
+		PrivacyStatus:            string(video.PrivacyStatus),
+		MadeForKids:              video.MadeForKids,
+		RegionRestrictionAllowed: regionRestrictionAllowed(video.RegionRestriction),
+		RegionRestrictionBlocked: regionRestrictionBlocked(video.RegionRestriction),
+		HasCaption:               video.HasCaption,
+		Definition:               string(video.Definition),
+		Class:                    string(video.Class),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create video: %w", err)
@@ -160,10 +314,54 @@ func (r *youtubeDBRepository) CreateVideo(ctx context.Context, video *model.YouT
 	return nil
 }
 
+func (r *youtubeDBRepository) UpsertVideo(ctx context.Context, video *model.YouTubeVideo) (repository.UpsertResult, error) {
+	result, err := upsertResult(r.q.UpsertYouTubeVideo(ctx, db.UpsertYouTubeVideoParams{
+		VideoID:                  string(video.ID),
+		Title:                    video.Title,
+		Description:              video.Description,
+		Duration:                 video.Duration,
+		ThumbnailDefaultUrl:      urlToString(video.Thumbnails.Default),
+		ThumbnailMediumUrl:       urlToString(video.Thumbnails.Medium),
+		ThumbnailHighUrl:         urlToString(video.Thumbnails.High),
+		ThumbnailStandardUrl:     urlToString(video.Thumbnails.Standard),
+		ThumbnailMaxresUrl:       urlToString(video.Thumbnails.Maxres),
+		PublishedAt:              video.PublishedAt,
+		BroadcastState:           string(video.BroadcastState),
+		Tags:                     nonNilStrings(video.Tags),
+		CategoryID:               video.CategoryID,
+		DefaultAudioLanguage:     video.DefaultAudioLanguage,
+		PrivacyStatus:            string(video.PrivacyStatus),
+		MadeForKids:              video.MadeForKids,
+		RegionRestrictionAllowed: regionRestrictionAllowed(video.RegionRestriction),
+		RegionRestrictionBlocked: regionRestrictionBlocked(video.RegionRestriction),
+		HasCaption:               video.HasCaption,
+		Definition:               string(video.Definition),
+		Class:                    string(video.Class),
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert video: %w", err)
+	}
+
+	// Upsert live streaming details if available
+	if video.LiveStreamingDetails != nil {
+		detailsResult, err := r.UpsertVideoLiveStreamingDetails(ctx, video.ID, video.LiveStreamingDetails)
+		if err != nil {
+			return result, fmt.Errorf("failed to upsert video live streaming details: %w", err)
+		}
+
+		// A video whose only change is in its live streaming details is still an updated video.
+		if result == repository.UpsertResultUnchanged && detailsResult != repository.UpsertResultUnchanged {
+			result = repository.UpsertResultUpdated
+		}
+	}
+
+	return result, nil
+}
+
 func (r *youtubeDBRepository) GetVideo(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeVideo, error) {
 	dbVideo, err := r.q.GetYouTubeVideo(ctx, string(videoID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get video: %w", err)
+		return nil, fmt.Errorf("failed to get video: %w", dbError(err))
 	}
 
 	video, err := convertYouTubeVideo(dbVideo)
@@ -176,19 +374,22 @@ func (r *youtubeDBRepository) GetVideo(ctx context.Context, videoID model.YouTub
 	if err == nil {
 		video.LiveStreamingDetails = convertYouTubeVideoLiveStreamingDetails(dbLiveDetails)
 	} else if !errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("failed to get video live streaming details: %w", err)
+		return nil, fmt.Errorf("failed to get video live streaming details: %w", dbError(err))
 	}
 
 	return video, nil
 }
 
-func (r *youtubeDBRepository) ListVideos(ctx context.Context, videoIDs []model.YouTubeVideoID) ([]*model.YouTubeVideo, error) {
+func (r *youtubeDBRepository) ListVideos(ctx context.Context, videoIDs []model.YouTubeVideoID, filter repository.YouTubeVideoFilter) ([]*model.YouTubeVideo, error) {
 	ids := make([]string, len(videoIDs))
 	for i, id := range videoIDs {
 		ids[i] = string(id)
 	}
 
-	dbVideos, err := r.q.ListYouTubeVideos(ctx, ids)
+	dbVideos, err := r.q.ListYouTubeVideos(ctx, db.ListYouTubeVideosParams{
+		VideoIds: ids,
+		Class:    videoClassFilter(filter),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list videos: %w", err)
 	}
@@ -205,7 +406,7 @@ func (r *youtubeDBRepository) ListVideos(ctx context.Context, videoIDs []model.Y
 		if err == nil {
 			video.LiveStreamingDetails = convertYouTubeVideoLiveStreamingDetails(dbLiveDetails)
 		} else if !errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("failed to get video live streaming details: %w", err)
+			return nil, fmt.Errorf("failed to get video live streaming details: %w", dbError(err))
 		}
 
 		videos[i] = video
@@ -214,12 +415,235 @@ func (r *youtubeDBRepository) ListVideos(ctx context.Context, videoIDs []model.Y
 	return videos, nil
 }
 
+func (r *youtubeDBRepository) ListVideoIDsByChannel(ctx context.Context, channelID model.YouTubeChannelID) ([]model.YouTubeVideoID, error) {
+	ids, err := r.q.ListYouTubeVideoIDsByChannel(ctx, string(channelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video IDs by channel: %w", err)
+	}
+
+	videoIDs := make([]model.YouTubeVideoID, len(ids))
+	for i, id := range ids {
+		videoIDs[i] = model.YouTubeVideoID(id)
+	}
+
+	return videoIDs, nil
+}
+
+// ----- Video thumbnail archive operations -----
+
+// SaveVideoThumbnailArchive records an archived image of a thumbnail of the video, and records its hash
+// next to the thumbnail URL unless the URL has changed since. It reports whether the image is new.
+// It should be run in a transaction.
+func (r *youtubeDBRepository) SaveVideoThumbnailArchive(ctx context.Context, videoID model.YouTubeVideoID, archive *model.YouTubeThumbnailArchive) (bool, error) {
+	err := r.q.SetYouTubeVideoThumbnailSHA256(ctx, db.SetYouTubeVideoThumbnailSHA256Params{
+		Size:    string(archive.Size),
+		Sha256:  archive.SHA256,
+		VideoID: string(videoID),
+		Url:     archive.URL.String(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to set video thumbnail sha256: %w", err)
+	}
+
+	rows, err := r.q.CreateYouTubeVideoThumbnailArchive(ctx, db.CreateYouTubeVideoThumbnailArchiveParams{
+		VideoID:    string(videoID),
+		Size:       string(archive.Size),
+		Sha256:     archive.SHA256,
+		Url:        archive.URL.String(),
+		ArchivedAt: archive.ArchivedAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create video thumbnail archive: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *youtubeDBRepository) ListVideoThumbnailArchives(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeThumbnailArchive, error) {
+	dbArchives, err := r.q.ListYouTubeVideoThumbnailArchives(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video thumbnail archives: %w", err)
+	}
+
+	archives := make([]*model.YouTubeThumbnailArchive, len(dbArchives))
+	for i, dbArchive := range dbArchives {
+		u, err := url.Parse(dbArchive.Url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse url: %w", err)
+		}
+
+		archives[i] = &model.YouTubeThumbnailArchive{
+			Size:       model.YouTubeThumbnailSize(dbArchive.Size),
+			SHA256:     dbArchive.Sha256,
+			URL:        u,
+			ArchivedAt: dbArchive.ArchivedAt,
+		}
+	}
+
+	return archives, nil
+}
+
+// SetVideoThumbnailMissing records that the image of the thumbnail of the size at u has been removed
+// from YouTube, so it is not downloaded again, unless the URL of the thumbnail has changed since.
+func (r *youtubeDBRepository) SetVideoThumbnailMissing(ctx context.Context, videoID model.YouTubeVideoID, size model.YouTubeThumbnailSize, u *url.URL, missingAt time.Time) error {
+	err := r.q.SetYouTubeVideoThumbnailMissingAt(ctx, db.SetYouTubeVideoThumbnailMissingAtParams{
+		Size:      string(size),
+		MissingAt: missingAt,
+		VideoID:   string(videoID),
+		Url:       u.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set video thumbnail missing at: %w", err)
+	}
+	return nil
+}
+
+func (r *youtubeDBRepository) SetVideoThumbnailsChecked(ctx context.Context, videoID model.YouTubeVideoID, checkedAt time.Time) error {
+	err := r.q.SetYouTubeVideoThumbnailsCheckedAt(ctx, db.SetYouTubeVideoThumbnailsCheckedAtParams{
+		CheckedAt: checkedAt,
+		VideoID:   string(videoID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set video thumbnails checked at: %w", err)
+	}
+	return nil
+}
+
+// ListVideoIDsWithThumbnailsToArchive lists the videos that have a thumbnail whose image at the
+// current URL is neither archived nor known to be removed, or whose thumbnails have not been
+// checked since checkedBefore, newest first.
+func (r *youtubeDBRepository) ListVideoIDsWithThumbnailsToArchive(ctx context.Context, checkedBefore time.Time) ([]model.YouTubeVideoID, error) {
+	dbVideoIDs, err := r.q.ListYouTubeVideoIDsWithThumbnailsToArchive(ctx, checkedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video IDs with thumbnails to archive: %w", err)
+	}
+
+	videoIDs := make([]model.YouTubeVideoID, len(dbVideoIDs))
+	for i, id := range dbVideoIDs {
+		videoIDs[i] = model.YouTubeVideoID(id)
+	}
+
+	return videoIDs, nil
+}
+
+// ----- Video availability operations -----
+
+// SetVideoAvailability updates the availability of the video and records the change,
+// reporting whether it changed. Unknown videos are ignored.
+func (r *youtubeDBRepository) SetVideoAvailability(ctx context.Context, videoID model.YouTubeVideoID, availability model.YouTubeVideoAvailability, observedAt time.Time) (bool, error) {
+	updated, err := r.q.UpdateYouTubeVideoAvailability(ctx, db.UpdateYouTubeVideoAvailabilityParams{
+		Availability: string(availability),
+		VideoID:      string(videoID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update video availability: %w", err)
+	}
+	if updated == 0 {
+		return false, nil
+	}
+
+	err = r.q.CreateYouTubeVideoAvailabilityChange(ctx, db.CreateYouTubeVideoAvailabilityChangeParams{
+		VideoID:      string(videoID),
+		ObservedAt:   observedAt,
+		Availability: string(availability),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create video availability change: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *youtubeDBRepository) ListVideoAvailabilityChanges(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeVideoAvailabilityChange, error) {
+	dbChanges, err := r.q.ListYouTubeVideoAvailabilityChanges(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video availability changes: %w", err)
+	}
+
+	changes := make([]*model.YouTubeVideoAvailabilityChange, len(dbChanges))
+	for i, dbChange := range dbChanges {
+		changes[i] = &model.YouTubeVideoAvailabilityChange{
+			ObservedAt:   dbChange.ObservedAt,
+			Availability: model.YouTubeVideoAvailability(dbChange.Availability),
+		}
+	}
+
+	return changes, nil
+}
+
+// ----- Video statistics operations -----
+
+func (r *youtubeDBRepository) CreateVideoStatistics(ctx context.Context, videoID model.YouTubeVideoID, snapshot *model.YouTubeVideoStatisticsSnapshot) error {
+	err := r.q.CreateYouTubeVideoStatistics(ctx, db.CreateYouTubeVideoStatisticsParams{
+		VideoID:      string(videoID),
+		CapturedAt:   snapshot.CapturedAt,
+		ViewCount:    snapshot.Statistics.ViewCount,
+		LikeCount:    snapshot.Statistics.LikeCount,
+		CommentCount: snapshot.Statistics.CommentCount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create video statistics: %w", err)
+	}
+	return nil
+}
+
+func (r *youtubeDBRepository) GetLatestVideoStatistics(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeVideoStatisticsSnapshot, error) {
+	dbStatistics, err := r.q.GetLatestYouTubeVideoStatistics(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest video statistics: %w", dbError(err))
+	}
+
+	return convertYouTubeVideoStatistics(dbStatistics), nil
+}
+
+func (r *youtubeDBRepository) ListVideoStatistics(ctx context.Context, videoID model.YouTubeVideoID, since time.Time, until time.Time) ([]*model.YouTubeVideoStatisticsSnapshot, error) {
+	dbStatistics, err := r.q.ListYouTubeVideoStatistics(ctx, db.ListYouTubeVideoStatisticsParams{
+		VideoID: string(videoID),
+		Since:   since,
+		Until:   until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video statistics: %w", err)
+	}
+
+	snapshots := make([]*model.YouTubeVideoStatisticsSnapshot, len(dbStatistics))
+	for i, dbStatistic := range dbStatistics {
+		snapshots[i] = convertYouTubeVideoStatistics(dbStatistic)
+	}
+
+	return snapshots, nil
+}
+
+func (r *youtubeDBRepository) ListFastestGrowingVideos(ctx context.Context, since time.Time, until time.Time, maxResults int32) ([]*model.YouTubeVideoViewGain, error) {
+	rows, err := r.q.ListFastestGrowingYouTubeVideos(ctx, db.ListFastestGrowingYouTubeVideosParams{
+		Since:      since,
+		Until:      until,
+		MaxResults: maxResults,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fastest growing videos: %w", err)
+	}
+
+	gains := make([]*model.YouTubeVideoViewGain, len(rows))
+	for i, row := range rows {
+		gains[i] = &model.YouTubeVideoViewGain{
+			VideoID:  model.YouTubeVideoID(row.VideoID),
+			ViewGain: row.ViewGain,
+		}
+	}
+
+	return gains, nil
+}
+
 // ----- Playlist-Video relationship operations -----
 
-func (r *youtubeDBRepository) CreatePlaylistVideo(ctx context.Context, playlistID model.YouTubePlaylistID, videoID model.YouTubeVideoID) error {
+func (r *youtubeDBRepository) CreatePlaylistVideo(ctx context.Context, playlistID model.YouTubePlaylistID, item *model.YouTubePlaylistItem) error {
 	err := r.q.CreateYouTubePlaylistVideo(ctx, db.CreateYouTubePlaylistVideoParams{
-		PlaylistID: string(playlistID),
-		VideoID:    string(videoID),
+		PlaylistID:       string(playlistID),
+		VideoID:          string(item.VideoID),
+		AddedAt:          item.AddedAt,
+		Position:         int32(item.Position),
+		VideoPublishedAt: item.VideoPublishedAt,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create playlist video: %w", err)
@@ -227,8 +651,59 @@ func (r *youtubeDBRepository) CreatePlaylistVideo(ctx context.Context, playlistI
 	return nil
 }
 
-func (r *youtubeDBRepository) ListVideoIDsByPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID) ([]model.YouTubeVideoID, error) {
-	ids, err := r.q.ListYouTubePlaylistVideoIDs(ctx, string(playlistID))
+// UpsertPlaylistVideo saves the membership of the video added at item.AddedAt. A membership saved
+// without the time it was added is taken as this one. It should be run in a transaction.
+func (r *youtubeDBRepository) UpsertPlaylistVideo(ctx context.Context, playlistID model.YouTubePlaylistID, item *model.YouTubePlaylistItem) (repository.UpsertResult, error) {
+	err := r.q.FillYouTubePlaylistVideoAddedAt(ctx, db.FillYouTubePlaylistVideoAddedAtParams{
+		AddedAt:    item.AddedAt,
+		PlaylistID: string(playlistID),
+		VideoID:    string(item.VideoID),
+	})
+	if err != nil {
+		return repository.UpsertResultUnchanged, fmt.Errorf("failed to fill playlist video added at: %w", err)
+	}
+
+	result, err := upsertResult(r.q.UpsertYouTubePlaylistVideo(ctx, db.UpsertYouTubePlaylistVideoParams{
+		PlaylistID:       string(playlistID),
+		VideoID:          string(item.VideoID),
+		AddedAt:          item.AddedAt,
+		Position:         int32(item.Position),
+		VideoPublishedAt: item.VideoPublishedAt,
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert playlist video: %w", err)
+	}
+	return result, nil
+}
+
+// RemovePlaylistVideos marks the memberships that are open in the database but not in listedItems
+// as removed at removedAt, and returns how many were removed. A membership is matched to an item
+// by its video and the time it was added.
+func (r *youtubeDBRepository) RemovePlaylistVideos(ctx context.Context, playlistID model.YouTubePlaylistID, listedItems []*model.YouTubePlaylistItem, removedAt time.Time) (int64, error) {
+	videoIDs := make([]string, len(listedItems))
+	addedAts := make([]time.Time, len(listedItems))
+	for i, item := range listedItems {
+		videoIDs[i] = string(item.VideoID)
+		addedAts[i] = item.AddedAt
+	}
+
+	removed, err := r.q.RemoveYouTubePlaylistVideos(ctx, db.RemoveYouTubePlaylistVideosParams{
+		RemovedAt:      removedAt,
+		PlaylistID:     string(playlistID),
+		ListedVideoIds: videoIDs,
+		ListedAddedAts: addedAts,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove playlist videos: %w", err)
+	}
+	return removed, nil
+}
+
+func (r *youtubeDBRepository) ListVideoIDsByPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID, filter repository.YouTubeVideoFilter) ([]model.YouTubeVideoID, error) {
+	ids, err := r.q.ListYouTubePlaylistVideoIDs(ctx, db.ListYouTubePlaylistVideoIDsParams{
+		PlaylistID: string(playlistID),
+		Class:      videoClassFilter(filter),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list video IDs by playlist: %w", err)
 	}
@@ -241,8 +716,49 @@ func (r *youtubeDBRepository) ListVideoIDsByPlaylist(ctx context.Context, playli
 	return videoIDs, nil
 }
 
+func (r *youtubeDBRepository) ListPlaylistMemberships(ctx context.Context, playlistID model.YouTubePlaylistID) ([]*model.YouTubePlaylistMembership, error) {
+	dbPlaylistVideos, err := r.q.ListYouTubePlaylistVideos(ctx, string(playlistID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playlist memberships: %w", err)
+	}
+
+	memberships := make([]*model.YouTubePlaylistMembership, len(dbPlaylistVideos))
+	for i, dbPlaylistVideo := range dbPlaylistVideos {
+		memberships[i] = &model.YouTubePlaylistMembership{
+			YouTubePlaylistItem: model.YouTubePlaylistItem{
+				VideoID:          model.YouTubeVideoID(dbPlaylistVideo.VideoID),
+				Position:         int64(dbPlaylistVideo.Position),
+				AddedAt:          dbPlaylistVideo.AddedAt,
+				VideoPublishedAt: dbPlaylistVideo.VideoPublishedAt,
+			},
+			RemovedAt: dbPlaylistVideo.RemovedAt,
+		}
+	}
+
+	return memberships, nil
+}
+
 // ----- Live streaming details operations -----
 
+// ListLiveVideoIDs lists the broadcasts that started since `since` and are live as far as
+// the database knows, and the upcoming ones scheduled to start in [since, until].
+func (r *youtubeDBRepository) ListLiveVideoIDs(ctx context.Context, since time.Time, until time.Time) ([]model.YouTubeVideoID, error) {
+	dbVideoIDs, err := r.q.ListLiveYouTubeVideoIDs(ctx, db.ListLiveYouTubeVideoIDsParams{
+		Since: since,
+		Until: until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list live video IDs: %w", err)
+	}
+
+	videoIDs := make([]model.YouTubeVideoID, len(dbVideoIDs))
+	for i, id := range dbVideoIDs {
+		videoIDs[i] = model.YouTubeVideoID(id)
+	}
+
+	return videoIDs, nil
+}
+
 func (r *youtubeDBRepository) CreateVideoLiveStreamingDetails(ctx context.Context, videoID model.YouTubeVideoID, details *model.YouTubeVideoLiveStreamingDetails) error {
 	err := r.q.CreateYouTubeVideoLiveStreamingDetails(ctx, db.CreateYouTubeVideoLiveStreamingDetailsParams{
 		VideoID:            string(videoID),
@@ -256,10 +772,23 @@ func (r *youtubeDBRepository) CreateVideoLiveStreamingDetails(ctx context.Contex
 	return nil
 }
 
+func (r *youtubeDBRepository) UpsertVideoLiveStreamingDetails(ctx context.Context, videoID model.YouTubeVideoID, details *model.YouTubeVideoLiveStreamingDetails) (repository.UpsertResult, error) {
+	result, err := upsertResult(r.q.UpsertYouTubeVideoLiveStreamingDetails(ctx, db.UpsertYouTubeVideoLiveStreamingDetailsParams{
+		VideoID:            string(videoID),
+		ActualStartTime:    details.ActualStartTime,
+		ActualEndTime:      details.ActualEndTime,
+		ScheduledStartTime: details.ScheduledStart,
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert video live streaming details: %w", err)
+	}
+	return result, nil
+}
+
 func (r *youtubeDBRepository) GetVideoLiveStreamingDetails(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeVideoLiveStreamingDetails, error) {
 	dbDetails, err := r.q.GetYouTubeVideoLiveStreamingDetails(ctx, string(videoID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get video live streaming details: %w", err)
+		return nil, fmt.Errorf("failed to get video live streaming details: %w", dbError(err))
 	}
 
 	return &model.YouTubeVideoLiveStreamingDetails{
@@ -269,51 +798,513 @@ func (r *youtubeDBRepository) GetVideoLiveStreamingDetails(ctx context.Context,
 	}, nil
 }
 
+// ----- Comment operations -----
+
+func (r *youtubeDBRepository) UpsertComment(ctx context.Context, comment *model.YouTubeComment) (repository.UpsertResult, error) {
+	var parentID *string
+	if comment.ParentID != nil {
+		id := string(*comment.ParentID)
+		parentID = &id
+	}
+
+	result, err := upsertResult(r.q.UpsertYouTubeComment(ctx, db.UpsertYouTubeCommentParams{
+		CommentID:         string(comment.ID),
+		VideoID:           string(comment.VideoID),
+		ParentID:          parentID,
+		AuthorDisplayName: comment.AuthorDisplayName,
+		AuthorChannelID:   string(comment.AuthorChannelID),
+		Text:              comment.Text,
+		LikeCount:         comment.LikeCount,
+		ReplyCount:        comment.ReplyCount,
+		PublishedAt:       comment.PublishedAt,
+		UpdatedAt:         comment.UpdatedAt,
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert comment: %w", err)
+	}
+	return result, nil
+}
+
+// GetCommentSync returns how far the comments of the video have been synced.
+// It returns ErrNotFound if they have never been synced.
+func (r *youtubeDBRepository) GetCommentSync(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeCommentSync, error) {
+	dbSync, err := r.q.GetYouTubeCommentSync(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment sync: %w", dbError(err))
+	}
+
+	return &model.YouTubeCommentSync{
+		SyncedAt:    dbSync.SyncedAt,
+		RefreshedAt: dbSync.RefreshedAt,
+	}, nil
+}
+
+func (r *youtubeDBRepository) SetCommentSync(ctx context.Context, videoID model.YouTubeVideoID, sync *model.YouTubeCommentSync) error {
+	err := r.q.UpsertYouTubeCommentSync(ctx, db.UpsertYouTubeCommentSyncParams{
+		VideoID:     string(videoID),
+		SyncedAt:    sync.SyncedAt,
+		RefreshedAt: sync.RefreshedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set comment sync: %w", err)
+	}
+	return nil
+}
+
+func (r *youtubeDBRepository) ListCommentsByVideo(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeComment, error) {
+	dbComments, err := r.q.ListYouTubeCommentsByVideo(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments by video: %w", err)
+	}
+
+	comments := make([]*model.YouTubeComment, len(dbComments))
+	for i, dbComment := range dbComments {
+		var parentID *model.YouTubeCommentID
+		if dbComment.ParentID != nil {
+			id := model.YouTubeCommentID(*dbComment.ParentID)
+			parentID = &id
+		}
+
+		comments[i] = &model.YouTubeComment{
+			ID:                model.YouTubeCommentID(dbComment.CommentID),
+			VideoID:           model.YouTubeVideoID(dbComment.VideoID),
+			ParentID:          parentID,
+			AuthorDisplayName: dbComment.AuthorDisplayName,
+			AuthorChannelID:   model.YouTubeChannelID(dbComment.AuthorChannelID),
+			Text:              dbComment.Text,
+			LikeCount:         dbComment.LikeCount,
+			ReplyCount:        dbComment.ReplyCount,
+			PublishedAt:       dbComment.PublishedAt,
+			UpdatedAt:         dbComment.UpdatedAt,
+		}
+	}
+
+	return comments, nil
+}
+
+// ----- Caption operations -----
+
+func (r *youtubeDBRepository) UpsertCaptionTrack(ctx context.Context, track *model.YouTubeCaptionTrack) (repository.UpsertResult, error) {
+	result, err := upsertResult(r.q.UpsertYouTubeCaptionTrack(ctx, db.UpsertYouTubeCaptionTrackParams{
+		CaptionID: string(track.ID),
+		VideoID:   string(track.VideoID),
+		Language:  track.Language,
+		Name:      track.Name,
+		TrackKind: string(track.TrackKind),
+		UpdatedAt: track.UpdatedAt,
+	}))
+	if err != nil {
+		return result, fmt.Errorf("failed to upsert caption track: %w", err)
+	}
+	return result, nil
+}
+
+func (r *youtubeDBRepository) ListCaptionTracks(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeCaptionTrack, error) {
+	dbTracks, err := r.q.ListYouTubeCaptionTracksByVideo(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list caption tracks: %w", err)
+	}
+
+	tracks := make([]*model.YouTubeCaptionTrack, len(dbTracks))
+	for i, dbTrack := range dbTracks {
+		tracks[i] = convertYouTubeCaptionTrack(dbTrack)
+	}
+
+	return tracks, nil
+}
+
+// ListStaleCaptionTracks lists the caption tracks of the video whose segments
+// have not been saved since the track was last updated.
+func (r *youtubeDBRepository) ListStaleCaptionTracks(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeCaptionTrack, error) {
+	dbTracks, err := r.q.ListStaleYouTubeCaptionTracksByVideo(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale caption tracks: %w", err)
+	}
+
+	tracks := make([]*model.YouTubeCaptionTrack, len(dbTracks))
+	for i, dbTrack := range dbTracks {
+		tracks[i] = convertYouTubeCaptionTrack(dbTrack)
+	}
+
+	return tracks, nil
+}
+
+// ReplaceCaptionSegments replaces the saved segments of the track with segments,
+// and marks them as up to date with the track. It should be run in a transaction.
+func (r *youtubeDBRepository) ReplaceCaptionSegments(ctx context.Context, track *model.YouTubeCaptionTrack, segments []*model.YouTubeCaptionSegment) error {
+	if err := r.q.DeleteYouTubeCaptionSegments(ctx, string(track.ID)); err != nil {
+		return fmt.Errorf("failed to delete caption segments: %w", err)
+	}
+
+	for i, segment := range segments {
+		if err := r.q.CreateYouTubeCaptionSegment(ctx, db.CreateYouTubeCaptionSegmentParams{
+			CaptionID:   string(track.ID),
+			Seq:         int32(i),
+			StartOffset: segment.Start,
+			EndOffset:   segment.End,
+			Text:        segment.Text,
+		}); err != nil {
+			return fmt.Errorf("failed to create caption segment: %w", err)
+		}
+	}
+
+	if err := r.q.SetYouTubeCaptionTrackSegmentsUpdatedAt(ctx, db.SetYouTubeCaptionTrackSegmentsUpdatedAtParams{
+		CaptionID:         string(track.ID),
+		SegmentsUpdatedAt: &track.UpdatedAt,
+	}); err != nil {
+		return fmt.Errorf("failed to set caption track segments updated at: %w", err)
+	}
+
+	return nil
+}
+
+func (r *youtubeDBRepository) ListCaptionSegments(ctx context.Context, captionID model.YouTubeCaptionID) ([]*model.YouTubeCaptionSegment, error) {
+	dbSegments, err := r.q.ListYouTubeCaptionSegments(ctx, string(captionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list caption segments: %w", err)
+	}
+
+	segments := make([]*model.YouTubeCaptionSegment, len(dbSegments))
+	for i, dbSegment := range dbSegments {
+		segments[i] = &model.YouTubeCaptionSegment{
+			Start: dbSegment.StartOffset,
+			End:   dbSegment.EndOffset,
+			Text:  dbSegment.Text,
+		}
+	}
+
+	return segments, nil
+}
+
+// ----- Live chat operations -----
+
+// CreateLiveChatMessages saves the messages posted to the live chat of the video,
+// skipping the ones already saved. It returns the number of messages newly saved.
+func (r *youtubeDBRepository) CreateLiveChatMessages(ctx context.Context, videoID model.YouTubeVideoID, messages []*model.YouTubeLiveChatMessage) (int64, error) {
+	var created int64
+	for _, message := range messages {
+		params := db.CreateYouTubeLiveChatMessageParams{
+			MessageID:         string(message.ID),
+			VideoID:           string(videoID),
+			MessageType:       string(message.Type),
+			AuthorChannelID:   string(message.Author.ChannelID),
+			AuthorDisplayName: message.Author.DisplayName,
+			AuthorIsOwner:     message.Author.IsOwner,
+			AuthorIsModerator: message.Author.IsModerator,
+			AuthorIsMember:    message.Author.IsMember,
+			Text:              message.Text,
+			PublishedAt:       message.PublishedAt,
+		}
+		if message.SuperChat != nil {
+			params.SuperChatAmountMicros = &message.SuperChat.AmountMicros
+			params.SuperChatCurrency = &message.SuperChat.Currency
+			params.SuperChatAmountDisplay = &message.SuperChat.AmountDisplay
+			params.SuperChatTier = &message.SuperChat.Tier
+		}
+		if message.Membership != nil {
+			params.MemberLevelName = &message.Membership.LevelName
+			if message.Type == model.YouTubeLiveChatMessageTypeMemberMilestone {
+				params.MemberMonths = &message.Membership.Months
+			}
+			if message.Type == model.YouTubeLiveChatMessageTypeMembershipGifting {
+				params.GiftCount = &message.Membership.GiftCount
+			}
+		}
+
+		rows, err := r.q.CreateYouTubeLiveChatMessage(ctx, params)
+		if err != nil {
+			return created, fmt.Errorf("failed to create live chat message: %w", err)
+		}
+		created += rows
+	}
+
+	return created, nil
+}
+
+func (r *youtubeDBRepository) ListLiveChatMessages(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeLiveChatMessage, error) {
+	dbMessages, err := r.q.ListYouTubeLiveChatMessages(ctx, string(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list live chat messages: %w", err)
+	}
+
+	messages := make([]*model.YouTubeLiveChatMessage, len(dbMessages))
+	for i, dbMessage := range dbMessages {
+		messages[i] = convertYouTubeLiveChatMessage(dbMessage)
+	}
+
+	return messages, nil
+}
+
+// ----- API quota operations -----
+
+var _ repository.YouTubeQuotaStore = &youtubeQuotaStore{}
+
+type youtubeQuotaStore struct {
+	q *db.Queries
+}
+
+// NewYouTubeQuotaStore returns a store of the API quota usage, kept apart from YouTubeDBRepository
+// since it is shared by every process using the same API key rather than being part of the archive.
+func NewYouTubeQuotaStore(conn db.DBTX) repository.YouTubeQuotaStore {
+	return &youtubeQuotaStore{
+		q: db.New(conn),
+	}
+}
+
+func (r *youtubeQuotaStore) ReserveAPIQuota(ctx context.Context, date time.Time, units int64, budget int64) (int64, error) {
+	used, err := r.q.ReserveYouTubeAPIQuota(ctx, db.ReserveYouTubeAPIQuotaParams{
+		UsageDate: date,
+		Units:     units,
+		Budget:    budget,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to reserve API quota: %w", repository.ErrQuotaBudgetExceeded)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve API quota: %w", err)
+	}
+	return used, nil
+}
+
+func (r *youtubeQuotaStore) GetAPIQuotaUsage(ctx context.Context, date time.Time) (int64, error) {
+	used, err := r.q.GetYouTubeAPIQuotaUsage(ctx, date)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get API quota usage: %w", err)
+	}
+	return used, nil
+}
+
+// ----- API response cache operations -----
+
+var _ repository.YouTubeAPIResponseCacheStore = &youtubeAPIResponseCacheStore{}
+
+type youtubeAPIResponseCacheStore struct {
+	q *db.Queries
+}
+
+func NewYouTubeAPIResponseCacheStore(conn db.DBTX) repository.YouTubeAPIResponseCacheStore {
+	return &youtubeAPIResponseCacheStore{
+		q: db.New(conn),
+	}
+}
+
+func (r *youtubeAPIResponseCacheStore) GetAPIResponseCache(ctx context.Context, key string) (*repository.YouTubeCachedResponse, error) {
+	dbCache, err := r.q.GetYouTubeAPIResponseCache(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API response cache: %w", dbError(err))
+	}
+
+	return &repository.YouTubeCachedResponse{
+		ETag: dbCache.Etag,
+		Body: dbCache.Body,
+	}, nil
+}
+
+func (r *youtubeAPIResponseCacheStore) PutAPIResponseCache(ctx context.Context, key string, response *repository.YouTubeCachedResponse) error {
+	err := r.q.UpsertYouTubeAPIResponseCache(ctx, db.UpsertYouTubeAPIResponseCacheParams{
+		CacheKey: key,
+		Etag:     response.ETag,
+		Body:     response.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put API response cache: %w", err)
+	}
+	return nil
+}
+
 // ----- Converters -----
 
+func convertYouTubeChannel(dbChannel db.YoutubeChannel) (*model.YouTubeChannel, error) {
+	avatarURL, err := stringToURL(dbChannel.AvatarUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avatar URL: %w", err)
+	}
+
+	bannerURL, err := stringToURL(dbChannel.BannerUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse banner URL: %w", err)
+	}
+
+	return &model.YouTubeChannel{
+		YouTubeChannelIdentity: model.YouTubeChannelIdentity{
+			ID:     model.YouTubeChannelID(dbChannel.ChannelID),
+			Handle: model.YouTubeChannelHandle(dbChannel.Handle),
+		},
+		UploadsPlaylistID: model.YouTubePlaylistID(dbChannel.UploadsPlaylistID),
+		Title:             dbChannel.Title,
+		Description:       dbChannel.Description,
+		Country:           dbChannel.Country,
+		AvatarURL:         avatarURL,
+		BannerURL:         bannerURL,
+		PublishedAt:       dbChannel.PublishedAt,
+	}, nil
+}
+
+func convertYouTubeChannelSnapshot(dbSnapshot db.YoutubeChannelSnapshot) (*model.YouTubeChannelSnapshot, error) {
+	avatarURL, err := stringToURL(dbSnapshot.AvatarUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avatar URL: %w", err)
+	}
+
+	bannerURL, err := stringToURL(dbSnapshot.BannerUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse banner URL: %w", err)
+	}
+
+	return &model.YouTubeChannelSnapshot{
+		CapturedAt:  dbSnapshot.CapturedAt,
+		Title:       dbSnapshot.Title,
+		Description: dbSnapshot.Description,
+		AvatarURL:   avatarURL,
+		BannerURL:   bannerURL,
+		Statistics: model.YouTubeChannelStatistics{
+			SubscriberCount: dbSnapshot.SubscriberCount,
+			ViewCount:       dbSnapshot.ViewCount,
+			VideoCount:      dbSnapshot.VideoCount,
+		},
+	}, nil
+}
+
+func convertYouTubePlaylist(dbPlaylist db.YoutubePlaylist) (*model.YouTubePlaylist, error) {
+	thumbnails, err := convertYouTubeThumbnails(
+		dbPlaylist.ThumbnailDefaultUrl,
+		dbPlaylist.ThumbnailMediumUrl,
+		dbPlaylist.ThumbnailHighUrl,
+		dbPlaylist.ThumbnailStandardUrl,
+		dbPlaylist.ThumbnailMaxresUrl,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert thumbnails: %w", err)
+	}
+
+	return &model.YouTubePlaylist{
+		ID:            model.YouTubePlaylistID(dbPlaylist.PlaylistID),
+		Title:         dbPlaylist.Title,
+		Description:   dbPlaylist.Description,
+		Thumbnails:    *thumbnails,
+		ItemCount:     dbPlaylist.ItemCount,
+		PrivacyStatus: model.YouTubePrivacyStatus(dbPlaylist.PrivacyStatus),
+		PublishedAt:   dbPlaylist.PublishedAt,
+	}, nil
+}
+
 func convertYouTubeVideo(dbVideo db.YoutubeVideo) (*model.YouTubeVideo, error) {
-	thumbnails, err := convertYouTubeVideoThumbnails(dbVideo)
+	thumbnails, err := convertYouTubeThumbnails(
+		dbVideo.ThumbnailDefaultUrl,
+		dbVideo.ThumbnailMediumUrl,
+		dbVideo.ThumbnailHighUrl,
+		dbVideo.ThumbnailStandardUrl,
+		dbVideo.ThumbnailMaxresUrl,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert thumbnails: %w", err)
 	}
 
 	return &model.YouTubeVideo{
-		ID:          model.YouTubeVideoID(dbVideo.VideoID),
-		Title:       dbVideo.Title,
-		Description: dbVideo.Description,
-		Duration:    dbVideo.Duration,
-		Thumbnails:  *thumbnails,
-		PublishedAt: dbVideo.PublishedAt,
+		ID:                   model.YouTubeVideoID(dbVideo.VideoID),
+		Title:                dbVideo.Title,
+		Description:          dbVideo.Description,
+		Duration:             dbVideo.Duration,
+		Thumbnails:           *thumbnails,
+		BroadcastState:       model.YouTubeBroadcastState(dbVideo.BroadcastState),
+		PublishedAt:          dbVideo.PublishedAt,
+		Tags:                 dbVideo.Tags,
+		CategoryID:           dbVideo.CategoryID,
+		DefaultAudioLanguage: dbVideo.DefaultAudioLanguage,
+		PrivacyStatus:        model.YouTubePrivacyStatus(dbVideo.PrivacyStatus),
+		MadeForKids:          dbVideo.MadeForKids,
+		RegionRestriction:    convertYouTubeRegionRestriction(dbVideo.RegionRestrictionAllowed, dbVideo.RegionRestrictionBlocked),
+		HasCaption:           dbVideo.HasCaption,
+		Definition:           model.YouTubeVideoDefinition(dbVideo.Definition),
+		Availability:         model.YouTubeVideoAvailability(dbVideo.Availability),
+		Class:                model.YouTubeVideoClass(dbVideo.Class),
+		ThumbnailHashes: model.YouTubeThumbnailHashes{
+			Default:  derefOr(dbVideo.ThumbnailDefaultSha256, ""),
+			Medium:   derefOr(dbVideo.ThumbnailMediumSha256, ""),
+			High:     derefOr(dbVideo.ThumbnailHighSha256, ""),
+			Standard: derefOr(dbVideo.ThumbnailStandardSha256, ""),
+			Maxres:   derefOr(dbVideo.ThumbnailMaxresSha256, ""),
+		},
+		ThumbnailsCheckedAt: dbVideo.ThumbnailsCheckedAt,
 	}, nil
 }
 
-func convertYouTubeVideoThumbnails(dbVideo db.YoutubeVideo) (*model.YouTubeVideoThumbnails, error) {
-	thumbnailDefaultURL, err := stringToURL(dbVideo.ThumbnailDefaultUrl)
+func convertYouTubeCaptionTrack(dbTrack db.YoutubeCaptionTrack) *model.YouTubeCaptionTrack {
+	return &model.YouTubeCaptionTrack{
+		ID:        model.YouTubeCaptionID(dbTrack.CaptionID),
+		VideoID:   model.YouTubeVideoID(dbTrack.VideoID),
+		Language:  dbTrack.Language,
+		Name:      dbTrack.Name,
+		TrackKind: model.YouTubeCaptionTrackKind(dbTrack.TrackKind),
+		UpdatedAt: dbTrack.UpdatedAt,
+	}
+}
+
+func convertYouTubeLiveChatMessage(dbMessage db.YoutubeLiveChatMessage) *model.YouTubeLiveChatMessage {
+	message := &model.YouTubeLiveChatMessage{
+		ID:   model.YouTubeLiveChatMessageID(dbMessage.MessageID),
+		Type: model.YouTubeLiveChatMessageType(dbMessage.MessageType),
+		Author: model.YouTubeLiveChatAuthor{
+			ChannelID:   model.YouTubeChannelID(dbMessage.AuthorChannelID),
+			DisplayName: dbMessage.AuthorDisplayName,
+			IsOwner:     dbMessage.AuthorIsOwner,
+			IsModerator: dbMessage.AuthorIsModerator,
+			IsMember:    dbMessage.AuthorIsMember,
+		},
+		Text:        dbMessage.Text,
+		PublishedAt: dbMessage.PublishedAt,
+	}
+
+	if dbMessage.SuperChatAmountMicros != nil {
+		message.SuperChat = &model.YouTubeSuperChat{
+			AmountMicros:  *dbMessage.SuperChatAmountMicros,
+			Currency:      derefOr(dbMessage.SuperChatCurrency, ""),
+			AmountDisplay: derefOr(dbMessage.SuperChatAmountDisplay, ""),
+			Tier:          derefOr(dbMessage.SuperChatTier, 0),
+		}
+	}
+
+	if dbMessage.MemberLevelName != nil {
+		message.Membership = &model.YouTubeMembershipEvent{
+			LevelName: *dbMessage.MemberLevelName,
+			Months:    derefOr(dbMessage.MemberMonths, 0),
+			GiftCount: derefOr(dbMessage.GiftCount, 0),
+		}
+	}
+
+	return message
+}
+
+func convertYouTubeThumbnails(defaultURL, mediumURL, highURL, standardURL, maxresURL *string) (*model.YouTubeThumbnails, error) {
+	thumbnailDefaultURL, err := stringToURL(defaultURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse thumbnail default URL: %w", err)
 	}
 
-	thumbnailMediumURL, err := stringToURL(dbVideo.ThumbnailMediumUrl)
+	thumbnailMediumURL, err := stringToURL(mediumURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse thumbnail medium URL: %w", err)
 	}
 
-	thumbnailHighURL, err := stringToURL(dbVideo.ThumbnailHighUrl)
+	thumbnailHighURL, err := stringToURL(highURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse thumbnail high URL: %w", err)
 	}
 
-	thumbnailStandardURL, err := stringToURL(dbVideo.ThumbnailStandardUrl)
+	thumbnailStandardURL, err := stringToURL(standardURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse thumbnail standard URL: %w", err)
 	}
 
-	thumbnailMaxresURL, err := stringToURL(dbVideo.ThumbnailMaxresUrl)
+	thumbnailMaxresURL, err := stringToURL(maxresURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse thumbnail maxres URL: %w", err)
 	}
 
-	return &model.YouTubeVideoThumbnails{
+	return &model.YouTubeThumbnails{
 		Default:  thumbnailDefaultURL,
 		Medium:   thumbnailMediumURL,
 		High:     thumbnailHighURL,
@@ -322,6 +1313,17 @@ func convertYouTubeVideoThumbnails(dbVideo db.YoutubeVideo) (*model.YouTubeVideo
 	}, nil
 }
 
+func convertYouTubeRegionRestriction(allowed []string, blocked []string) *model.YouTubeRegionRestriction {
+	if allowed == nil && blocked == nil {
+		return nil
+	}
+
+	return &model.YouTubeRegionRestriction{
+		Allowed: allowed,
+		Blocked: blocked,
+	}
+}
+
 func convertYouTubeVideoLiveStreamingDetails(dbLiveDetails db.YoutubeVideoLiveStreamingDetail) *model.YouTubeVideoLiveStreamingDetails {
 	return &model.YouTubeVideoLiveStreamingDetails{
 		ActualStartTime: dbLiveDetails.ActualStartTime,
@@ -330,8 +1332,36 @@ func convertYouTubeVideoLiveStreamingDetails(dbLiveDetails db.YoutubeVideoLiveSt
 	}
 }
 
+func convertYouTubeVideoStatistics(dbStatistics db.YoutubeVideoStatistic) *model.YouTubeVideoStatisticsSnapshot {
+	return &model.YouTubeVideoStatisticsSnapshot{
+		CapturedAt: dbStatistics.CapturedAt,
+		Statistics: model.YouTubeVideoStatistics{
+			ViewCount:    dbStatistics.ViewCount,
+			LikeCount:    dbStatistics.LikeCount,
+			CommentCount: dbStatistics.CommentCount,
+		},
+	}
+}
+
 // ----- Helper functions -----
 
+// upsertResult interprets the result of an Upsert* query, which returns whether the row
+// was inserted, or no row at all when the conflicting row already had the same values.
+func upsertResult(inserted bool, err error) (repository.UpsertResult, error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.UpsertResultUnchanged, nil
+	}
+	if err != nil {
+		return repository.UpsertResultUnchanged, err
+	}
+
+	if inserted {
+		return repository.UpsertResultInserted, nil
+	}
+
+	return repository.UpsertResultUpdated, nil
+}
+
 func urlToString(u *url.URL) *string {
 	if u == nil {
 		return nil
@@ -354,3 +1384,44 @@ func stringToURL(s *string) (*url.URL, error) {
 
 	return u, nil
 }
+
+// nonNilStrings returns an empty slice for nil, so that it is stored as an empty array instead of NULL.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func regionRestrictionAllowed(r *model.YouTubeRegionRestriction) []string {
+	if r == nil {
+		return nil
+	}
+	return r.Allowed
+}
+
+func regionRestrictionBlocked(r *model.YouTubeRegionRestriction) []string {
+	if r == nil {
+		return nil
+	}
+	return r.Blocked
+}
+
+// videoClassFilter returns the class to filter by, or nil for every class.
+func videoClassFilter(filter repository.YouTubeVideoFilter) *string {
+	if filter.Class == "" {
+		return nil
+	}
+
+	class := string(filter.Class)
+
+	return &class
+}
+
+// derefOr returns the value p points to, or fallback if p is nil.
+func derefOr[T any](p *T, fallback T) T {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
@@ -0,0 +1,57 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"google.golang.org/api/googleapi"
+)
+
+// youtubeError maps an error returned by the YouTube Data API client onto the repository errors.
+// The original *googleapi.Error stays in the chain and can still be retrieved with errors.As.
+func youtubeError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	for _, item := range apiErr.Errors {
+		switch item.Reason {
+		case "quotaExceeded", "dailyLimitExceeded":
+			return fmt.Errorf("%w: %w", repository.ErrQuotaExceeded, err)
+		case "rateLimitExceeded", "userRateLimitExceeded":
+			return fmt.Errorf("%w: %w", repository.ErrRateLimited, err)
+		case "liveChatEnded":
+			return fmt.Errorf("%w: %w", repository.ErrLiveChatEnded, err)
+		case "notFound", "channelNotFound", "playlistNotFound", "videoNotFound", "commentNotFound", "liveChatNotFound":
+			return fmt.Errorf("%w: %w", repository.ErrNotFound, err)
+		case "forbidden", "channelForbidden", "playlistForbidden", "playlistItemsNotAccessible", "commentsDisabled", "liveChatDisabled":
+			return fmt.Errorf("%w: %w", repository.ErrForbidden, err)
+		}
+	}
+
+	switch apiErr.Code {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: %w", repository.ErrUnauthorized, err)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", repository.ErrNotFound, err)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %w", repository.ErrForbidden, err)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", repository.ErrRateLimited, err)
+	}
+
+	return err
+}
+
+// dbError maps an error returned by the database onto the repository errors.
+func dbError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %w", repository.ErrNotFound, err)
+	}
+
+	return err
+}
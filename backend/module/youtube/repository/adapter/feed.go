@@ -0,0 +1,124 @@
+package adapter
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+var _ repository.YouTubeFeedRepository = &youtubeFeedRepository{}
+
+// youtubeFeedURL is the Atom feed of the latest videos of a channel. Unlike the Data API, it is free.
+const youtubeFeedURL = "https://www.youtube.com/feeds/videos.xml"
+
+type youtubeFeedRepository struct {
+	client *http.Client
+}
+
+// NewYouTubeFeedRepository returns a repository that fetches feeds with client.
+func NewYouTubeFeedRepository(client *http.Client) repository.YouTubeFeedRepository {
+	return &youtubeFeedRepository{
+		client: client,
+	}
+}
+
+func (r *youtubeFeedRepository) ListFeedEntries(ctx context.Context, channelID model.YouTubeChannelID) ([]*model.YouTubeFeedEntry, error) {
+	u := youtubeFeedURL + "?" + url.Values{"channel_id": {string(channelID)}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("failed to get feed of channel %s: %w", channelID, repository.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to get feed: unexpected status %s", resp.Status)
+	}
+
+	entries, err := parseYouTubeFeed(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// youtubeAtomFeed is the part of a YouTube Atom feed that is read.
+// Deleted entries only appear in the feeds pushed by the WebSub hub.
+type youtubeAtomFeed struct {
+	Entries        []youtubeAtomEntry        `xml:"http://www.w3.org/2005/Atom entry"`
+	DeletedEntries []youtubeAtomDeletedEntry `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+}
+
+type youtubeAtomEntry struct {
+	VideoID   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+	ChannelID string `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
+	Title     string `xml:"http://www.w3.org/2005/Atom title"`
+	Published string `xml:"http://www.w3.org/2005/Atom published"`
+	Updated   string `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+type youtubeAtomDeletedEntry struct {
+	Ref string `xml:"ref,attr"` // such as "yt:video:VIDEO_ID"
+}
+
+func parseYouTubeFeed(r io.Reader) ([]*model.YouTubeFeedEntry, error) {
+	var feed youtubeAtomFeed
+	if err := xml.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to decode feed: %w", err)
+	}
+
+	return feedEntriesFromYouTubeAtomFeed(feed)
+}
+
+func feedEntriesFromYouTubeAtomFeed(feed youtubeAtomFeed) ([]*model.YouTubeFeedEntry, error) {
+	entries := make([]*model.YouTubeFeedEntry, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		e, err := feedEntryFromYouTubeAtomEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert entry %s: %w", entry.VideoID, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func feedEntryFromYouTubeAtomEntry(entry youtubeAtomEntry) (*model.YouTubeFeedEntry, error) {
+	if entry.VideoID == "" {
+		return nil, fmt.Errorf("missing video ID")
+	}
+
+	publishedAt, err := time.Parse(time.RFC3339, entry.Published)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse published: %w", err)
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, entry.Updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated: %w", err)
+	}
+
+	return &model.YouTubeFeedEntry{
+		VideoID:     model.YouTubeVideoID(entry.VideoID),
+		ChannelID:   model.YouTubeChannelID(entry.ChannelID),
+		Title:       entry.Title,
+		PublishedAt: publishedAt,
+		UpdatedAt:   updatedAt,
+	}, nil
+}
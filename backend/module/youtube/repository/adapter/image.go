@@ -0,0 +1,58 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+var _ repository.YouTubeImageRepository = &youtubeImageRepository{}
+
+// maxImageSize is the largest image downloaded; maxres thumbnails are well under it.
+const maxImageSize = 16 << 20
+
+type youtubeImageRepository struct {
+	client *http.Client
+}
+
+// NewYouTubeImageRepository returns a repository that downloads images with client.
+func NewYouTubeImageRepository(client *http.Client) repository.YouTubeImageRepository {
+	return &youtubeImageRepository{
+		client: client,
+	}
+}
+
+func (r *youtubeImageRepository) GetImage(ctx context.Context, u *url.URL) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Removed thumbnails are answered with 404 and a gray placeholder image, which is not wanted.
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, "", fmt.Errorf("failed to get image %s: %w", u, repository.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("failed to get image %s: unexpected status %s", u, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return nil, "", fmt.Errorf("failed to read image %s: larger than %d bytes", u, maxImageSize)
+	}
+
+	return data, resp.Header.Get("Content-Type"), nil
+}
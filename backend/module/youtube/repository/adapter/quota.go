@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+// youtubeQuotaCosts is the quota cost of each YouTube Data API endpoint per request.
+// See https://developers.google.com/youtube/v3/determine_quota_cost
+var youtubeQuotaCosts = map[string]int64{
+	"captions.download":     200,
+	"captions.list":         50,
+	"channels.list":         1,
+	"commentThreads.list":   1,
+	"comments.list":         1,
+	"liveChatMessages.list": 5,
+	"playlists.list":        1,
+	"playlistItems.list":    1,
+	"videos.list":           1,
+}
+
+var _ repository.YouTubeQuotaMeter = &youtubeQuotaMeter{}
+
+// youtubeQuotaMeter persists the daily usage in the database,
+// so that the budget is shared by every process using the same API key.
+type youtubeQuotaMeter struct {
+	quotaStore  repository.YouTubeQuotaStore
+	dailyBudget int64
+	location    *time.Location
+}
+
+func NewYouTubeQuotaMeter(quotaStore repository.YouTubeQuotaStore, dailyBudget int64) (repository.YouTubeQuotaMeter, error) {
+	// The quota is reset at midnight Pacific Time.
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Pacific Time location: %w", err)
+	}
+
+	return &youtubeQuotaMeter{
+		quotaStore:  quotaStore,
+		dailyBudget: dailyBudget,
+		location:    location,
+	}, nil
+}
+
+func (m *youtubeQuotaMeter) Reserve(ctx context.Context, units int64) error {
+	if units > m.dailyBudget {
+		return fmt.Errorf("failed to reserve quota: %w", repository.ErrQuotaBudgetExceeded)
+	}
+
+	if _, err := m.quotaStore.ReserveAPIQuota(ctx, m.today(), units, m.dailyBudget); err != nil {
+		return fmt.Errorf("failed to reserve quota: %w", err)
+	}
+
+	return nil
+}
+
+func (m *youtubeQuotaMeter) Usage(ctx context.Context) (int64, error) {
+	used, err := m.quotaStore.GetAPIQuotaUsage(ctx, m.today())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+
+	return used, nil
+}
+
+// today returns the current date in Pacific Time as midnight UTC, as stored in the database.
+func (m *youtubeQuotaMeter) today() time.Time {
+	y, mo, d := time.Now().In(m.location).Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+}
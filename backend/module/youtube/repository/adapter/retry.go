@@ -0,0 +1,123 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// YouTubeRetryPolicy controls how failed requests to the YouTube Data API are retried.
+// Only transient errors are retried, waiting an exponentially growing backoff with jitter in between.
+type YouTubeRetryPolicy struct {
+	MaxRetries     int           // the number of retries after the first attempt; 0 disables retrying
+	InitialBackoff time.Duration // the backoff before the first retry
+	MaxBackoff     time.Duration // the upper bound of the backoff
+	Multiplier     float64       // the factor the backoff grows by on every retry
+}
+
+var DefaultYouTubeRetryPolicy = YouTubeRetryPolicy{
+	MaxRetries:     5,
+	InitialBackoff: 1 * time.Second,
+	MaxBackoff:     32 * time.Second,
+	Multiplier:     2,
+}
+
+// WithRetryPolicy replaces DefaultYouTubeRetryPolicy with policy.
+func WithRetryPolicy(policy YouTubeRetryPolicy) YouTubeRepositoryOption {
+	return func(r *youtubeRepository) {
+		r.retryPolicy = policy
+	}
+}
+
+// backoff returns how long to wait before the given retry, counted from 0.
+// It is picked at random from the upper half of the exponential backoff, so that
+// concurrent callers do not retry in lockstep.
+func (p YouTubeRetryPolicy) backoff(retry int) time.Duration {
+	backoff := min(float64(p.InitialBackoff)*math.Pow(p.Multiplier, float64(retry)), float64(p.MaxBackoff))
+	return time.Duration(backoff/2 + rand.Float64()*backoff/2)
+}
+
+// doCall makes a request to the endpoint with do, reserving its quota before every attempt
+// and retrying transient errors according to the retry policy. The returned error is mapped
+// by youtubeError. Since requests may be sent more than once, do must be idempotent.
+func doCall[T any](
+	ctx context.Context,
+	r *youtubeRepository,
+	endpoint string,
+	do func(opts ...googleapi.CallOption) (T, error),
+) (T, error) {
+	var zero T
+
+	for retry := 0; ; retry++ {
+		if err := r.reserveQuota(ctx, endpoint); err != nil {
+			return zero, err
+		}
+
+		response, err := do()
+		if err == nil {
+			return response, nil
+		}
+		if isNotModified(err) {
+			return zero, err
+		}
+
+		if !isRetryableYouTubeError(err) || ctx.Err() != nil {
+			return zero, youtubeError(err)
+		}
+		if retry >= r.retryPolicy.MaxRetries {
+			if retry > 0 {
+				log.Printf("youtube: %s failed (attempt %d/%d), giving up: %v", endpoint, retry+1, r.retryPolicy.MaxRetries+1, err)
+			}
+			return zero, youtubeError(err)
+		}
+
+		backoff := r.retryPolicy.backoff(retry)
+		log.Printf("youtube: %s failed (attempt %d/%d), retrying in %s: %v", endpoint, retry+1, r.retryPolicy.MaxRetries+1, backoff, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, context.Cause(ctx)
+		}
+	}
+}
+
+// isRetryableYouTubeError reports whether err is a transient error, after which the same request may succeed.
+func isRetryableYouTubeError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		case http.StatusForbidden:
+			// A rate limit is reported as 403 too, but unlike an exhausted quota it is lifted soon.
+			for _, item := range apiErr.Errors {
+				if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
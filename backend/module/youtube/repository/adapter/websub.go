@@ -0,0 +1,168 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+var _ repository.YouTubeWebSubHub = &youtubeWebSubHub{}
+
+// YouTubeWebSubHubURL is the hub that YouTube publishes channel feeds to.
+const YouTubeWebSubHubURL = "https://pubsubhubbub.appspot.com/subscribe"
+
+// youtubeWebSubTopicURL is the topic of a channel feed; the same feed as youtubeFeedURL under another path.
+const youtubeWebSubTopicURL = "https://www.youtube.com/xml/feeds/videos.xml"
+
+// webSubSignatureHashes are the algorithms a hub may sign notifications with, by the prefix of the signature.
+var webSubSignatureHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+type youtubeWebSubHub struct {
+	client *http.Client
+	hubURL string
+	secret string // shared with the hub to sign notifications
+}
+
+// NewYouTubeWebSubHub returns a hub client that subscribes at hubURL, usually YouTubeWebSubHubURL,
+// and accepts only the notifications signed with secret.
+func NewYouTubeWebSubHub(client *http.Client, hubURL string, secret string) repository.YouTubeWebSubHub {
+	return &youtubeWebSubHub{
+		client: client,
+		hubURL: hubURL,
+		secret: secret,
+	}
+}
+
+func (h *youtubeWebSubHub) Subscribe(ctx context.Context, channelID model.YouTubeChannelID, callbackURL string, lease time.Duration) error {
+	form := url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {webSubTopic(channelID)},
+		"hub.callback":      {callbackURL},
+		"hub.verify":        {"async"},
+		"hub.lease_seconds": {strconv.FormatInt(int64(lease/time.Second), 10)},
+		"hub.secret":        {h.secret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The hub answers 202 Accepted and verifies the intent of the subscriber afterwards.
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to subscribe: unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+func (h *youtubeWebSubHub) TopicChannelID(topic string) (model.YouTubeChannelID, bool) {
+	u, err := url.Parse(topic)
+	if err != nil {
+		return "", false
+	}
+
+	base := *u
+	base.RawQuery = ""
+	base.Scheme = "https"
+	if base.String() != youtubeWebSubTopicURL {
+		return "", false
+	}
+
+	channelID := u.Query().Get("channel_id")
+	if channelID == "" {
+		return "", false
+	}
+
+	return model.YouTubeChannelID(channelID), true
+}
+
+func (h *youtubeWebSubHub) ParseNotification(body []byte, signature string) (*model.YouTubeFeedNotification, error) {
+	if err := verifyWebSubSignature(body, signature, h.secret); err != nil {
+		return nil, err
+	}
+
+	var feed youtubeAtomFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to decode notification: %w", err)
+	}
+
+	entries, err := feedEntriesFromYouTubeAtomFeed(feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert notification: %w", err)
+	}
+
+	deletedVideoIDs := make([]model.YouTubeVideoID, 0, len(feed.DeletedEntries))
+	for _, entry := range feed.DeletedEntries {
+		videoID, ok := strings.CutPrefix(entry.Ref, "yt:video:")
+		if !ok {
+			continue
+		}
+		deletedVideoIDs = append(deletedVideoIDs, model.YouTubeVideoID(videoID))
+	}
+
+	return &model.YouTubeFeedNotification{
+		Entries:         entries,
+		DeletedVideoIDs: deletedVideoIDs,
+	}, nil
+}
+
+func webSubTopic(channelID model.YouTubeChannelID) string {
+	return youtubeWebSubTopicURL + "?" + url.Values{"channel_id": {string(channelID)}}.Encode()
+}
+
+// verifyWebSubSignature checks a signature header like "sha1=0123abcd...",
+// which is the HMAC of body keyed with secret.
+func verifyWebSubSignature(body []byte, signature string, secret string) error {
+	algorithm, digest, ok := strings.Cut(signature, "=")
+	if !ok {
+		return fmt.Errorf("%w: malformed signature", repository.ErrInvalidSignature)
+	}
+
+	newHash, ok := webSubSignatureHashes[algorithm]
+	if !ok {
+		return fmt.Errorf("%w: unsupported algorithm %s", repository.ErrInvalidSignature, algorithm)
+	}
+
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return fmt.Errorf("%w: malformed digest", repository.ErrInvalidSignature)
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Errorf("%w: digest mismatch", repository.ErrInvalidSignature)
+	}
+
+	return nil
+}
@@ -3,88 +3,184 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tocoteron/omigoto/backend/module/youtube/model"
 	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 )
 
-const YouTubeMaxResults = 50
-
 var _ repository.YouTubeRepository = &youtubeRepository{}
 
+// playerMaxWidth is the maximum width of the embedded player requested with videos.
+const playerMaxWidth = 1080
+
+// The comment endpoints accept up to 100 results per page, unlike the other list endpoints.
+const (
+	commentThreadsMaxResults = 100
+	commentsMaxResults       = 100
+)
+
+// liveChatMessagesMaxResults is the largest page liveChatMessages.list returns.
+const liveChatMessagesMaxResults = 2000
+
 type youtubeRepository struct {
-	service *youtube.Service
+	service       *youtube.Service
+	oauthService  *youtube.Service             // nil if no token source is given
+	tokenSource   oauth2.TokenSource           // nil if calls are made with the API key only
+	quotaMeter    repository.YouTubeQuotaMeter // nil if quota is not tracked
+	retryPolicy   YouTubeRetryPolicy
+	responseCache repository.YouTubeResponseCache // nil if responses are not cached
+}
+
+type YouTubeRepositoryOption func(*youtubeRepository)
+
+// WithQuotaMeter makes the repository reserve the quota of each call from meter before making it.
+func WithQuotaMeter(meter repository.YouTubeQuotaMeter) YouTubeRepositoryOption {
+	return func(r *youtubeRepository) {
+		r.quotaMeter = meter
+	}
+}
+
+// WithTokenSource makes the repository authorize the calls that need OAuth, such as caption
+// downloads, with tokens from ts. Without it, those calls fail with repository.ErrUnauthorized.
+func WithTokenSource(ts oauth2.TokenSource) YouTubeRepositoryOption {
+	return func(r *youtubeRepository) {
+		r.tokenSource = ts
+	}
 }
 
-func NewYouTubeRepository(ctx context.Context, apiKey string) (*youtubeRepository, error) {
+func NewYouTubeRepository(ctx context.Context, apiKey string, opts ...YouTubeRepositoryOption) (*youtubeRepository, error) {
 	service, err := youtube.NewService(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create youtube service: %w", err)
 	}
 
-	return &youtubeRepository{
-		service: service,
-	}, nil
+	r := &youtubeRepository{
+		service:     service,
+		retryPolicy: DefaultYouTubeRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.tokenSource != nil {
+		r.oauthService, err = youtube.NewService(ctx, option.WithTokenSource(r.tokenSource))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create youtube oauth service: %w", err)
+		}
+	}
+
+	return r, nil
 }
 
 func (r *youtubeRepository) GetChannel(
 	ctx context.Context,
 	channelID model.YouTubeChannelID,
 ) (*model.YouTubeChannel, error) {
-	call := r.service.Channels.List([]string{"contentDetails", "snippet"}).
-		Id(string(channelID)).
-		MaxResults(1)
+	parts := []string{"brandingSettings", "contentDetails", "snippet", "statistics"}
+
+	call := r.service.Channels.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"id":         {string(channelID)},
+		"maxResults": {"1"},
+	}
 
-	response, err := call.Do()
+	response, err := doCachedCall(ctx, r, "channels.list", params, call.IfNoneMatch, call.Context(ctx).Do)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get channel: %w", err)
 	}
 
 	if len(response.Items) == 0 {
-		return nil, fmt.Errorf("channel not found")
+		return nil, fmt.Errorf("channel %s: %w", channelID, repository.ErrNotFound)
 	}
 	if len(response.Items) > 1 {
 		return nil, fmt.Errorf("multiple channels found")
 	}
 
-	return &model.YouTubeChannel{
-		YouTubeChannelIdentity: model.YouTubeChannelIdentity{
-			ID:     channelID,
-			Handle: model.YouTubeChannelHandle(response.Items[0].Snippet.CustomUrl),
-		},
-		UploadsPlaylistID: model.YouTubePlaylistID(response.Items[0].ContentDetails.RelatedPlaylists.Uploads),
-	}, nil
+	channel, err := channelFromYouTubeChannel(response.Items[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+func (r *youtubeRepository) GetChannelByHandle(
+	ctx context.Context,
+	handle model.YouTubeChannelHandle,
+) (*model.YouTubeChannel, error) {
+	parts := []string{"brandingSettings", "contentDetails", "snippet", "statistics"}
+
+	call := r.service.Channels.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"forHandle":  {string(handle)},
+		"maxResults": {"1"},
+	}
+
+	response, err := doCachedCall(ctx, r, "channels.list", params, call.IfNoneMatch, call.Context(ctx).Do)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel by handle: %w", err)
+	}
+
+	if len(response.Items) == 0 {
+		return nil, fmt.Errorf("channel %s: %w", handle, repository.ErrNotFound)
+	}
+	if len(response.Items) > 1 {
+		return nil, fmt.Errorf("multiple channels found")
+	}
+
+	channel, err := channelFromYouTubeChannel(response.Items[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert channel: %w", err)
+	}
+
+	return channel, nil
 }
 
 func (r *youtubeRepository) GetPlaylist(
 	ctx context.Context,
 	playlistID model.YouTubePlaylistID,
 ) (*model.YouTubePlaylist, error) {
-	call := r.service.Playlists.List([]string{"snippet"}).
-		Id(string(playlistID)).
-		MaxResults(1)
+	parts := []string{"contentDetails", "snippet", "status"}
 
-	response, err := call.Do()
+	call := r.service.Playlists.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"id":         {string(playlistID)},
+		"maxResults": {"1"},
+	}
+
+	response, err := doCachedCall(ctx, r, "playlists.list", params, call.IfNoneMatch, call.Context(ctx).Do)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get playlist: %w", err)
 	}
 
 	if len(response.Items) == 0 {
-		return nil, fmt.Errorf("playlist not found")
+		return nil, fmt.Errorf("playlist %s: %w", playlistID, repository.ErrNotFound)
 	}
 	if len(response.Items) > 1 {
 		return nil, fmt.Errorf("multiple playlists found")
 	}
 
-	return &model.YouTubePlaylist{
-		ID:    model.YouTubePlaylistID(response.Items[0].Id),
-		Title: response.Items[0].Snippet.Title,
-	}, nil
+	playlist, err := playlistFromYouTubePlaylist(response.Items[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse playlist: %w", err)
+	}
+
+	return playlist, nil
 }
 
 func (r *youtubeRepository) ListPlaylists(
@@ -92,25 +188,33 @@ func (r *youtubeRepository) ListPlaylists(
 	channelID model.YouTubeChannelID,
 	pageToken *repository.YouTubePageToken,
 ) ([]*model.YouTubePlaylist, int64, *repository.YouTubePageToken, error) {
-	call := r.service.Playlists.List([]string{"snippet"}).
-		ChannelId(string(channelID)).
-		MaxResults(50) // max value is 50
+	parts := []string{"contentDetails", "snippet", "status"}
+
+	call := r.service.Playlists.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"channelId":  {string(channelID)},
+		"maxResults": {strconv.Itoa(repository.YouTubeMaxResults)},
+	}
 
 	if pageToken != nil {
-		call.PageToken(string(*pageToken))
+		params.Set("pageToken", string(*pageToken))
 	}
 
-	response, err := call.Do()
+	response, err := doCachedCall(ctx, r, "playlists.list", params, call.IfNoneMatch, call.Context(ctx).Do)
 	if err != nil {
 		return nil, 0, nil, fmt.Errorf("failed to list playlists: %w", err)
 	}
 
 	playlists := make([]*model.YouTubePlaylist, 0, len(response.Items))
 	for _, item := range response.Items {
-		playlists = append(playlists, &model.YouTubePlaylist{
-			ID:    model.YouTubePlaylistID(item.Id),
-			Title: item.Snippet.Title,
-		})
+		playlist, err := playlistFromYouTubePlaylist(item)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to parse playlist: %w", err)
+		}
+
+		playlists = append(playlists, playlist)
 	}
 
 	nextPageToken := pageTokenFromString(response.NextPageToken)
@@ -123,8 +227,8 @@ func (r *youtubeRepository) ListVideos(
 	videoIDs []model.YouTubeVideoID,
 	pageToken *repository.YouTubePageToken,
 ) ([]*model.YouTubeVideo, int64, *repository.YouTubePageToken, error) {
-	if len(videoIDs) > YouTubeMaxResults {
-		return nil, 0, nil, fmt.Errorf("videoIDs length must be less than or equal to %d", YouTubeMaxResults)
+	if len(videoIDs) > repository.YouTubeMaxResults {
+		return nil, 0, nil, fmt.Errorf("videoIDs length must be less than or equal to %d", repository.YouTubeMaxResults)
 	}
 
 	ids := make([]string, 0, len(videoIDs))
@@ -132,15 +236,23 @@ func (r *youtubeRepository) ListVideos(
 		ids = append(ids, string(id))
 	}
 
-	call := r.service.Videos.List([]string{"contentDetails", "snippet", "liveStreamingDetails"}).
-		Id(ids...).
-		MaxResults(YouTubeMaxResults)
+	parts := []string{"contentDetails", "snippet", "liveStreamingDetails", "player", "statistics", "status"}
+
+	call := r.service.Videos.List(parts)
+
+	// The embed size of the player tells the aspect ratio of the video only if the maximum size is set.
+	params := url.Values{
+		"part":       parts,
+		"id":         ids,
+		"maxWidth":   {strconv.Itoa(playerMaxWidth)},
+		"maxResults": {strconv.Itoa(repository.YouTubeMaxResults)},
+	}
 
 	if pageToken != nil {
-		call.PageToken(string(*pageToken))
+		params.Set("pageToken", string(*pageToken))
 	}
 
-	response, err := call.Do()
+	response, err := doCachedCall(ctx, r, "videos.list", params, call.IfNoneMatch, call.Context(ctx).Do)
 	if err != nil {
 		return nil, 0, nil, fmt.Errorf("failed to list videos: %w", err)
 	}
@@ -165,15 +277,21 @@ func (r *youtubeRepository) ListVideoIDsByPlaylist(
 	playlistID model.YouTubePlaylistID,
 	pageToken *repository.YouTubePageToken,
 ) ([]model.YouTubeVideoID, int64, *repository.YouTubePageToken, error) {
-	call := r.service.PlaylistItems.List([]string{"snippet"}).
-		PlaylistId(string(playlistID)).
-		MaxResults(YouTubeMaxResults)
+	parts := []string{"snippet"}
+
+	call := r.service.PlaylistItems.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"playlistId": {string(playlistID)},
+		"maxResults": {strconv.Itoa(repository.YouTubeMaxResults)},
+	}
 
 	if pageToken != nil {
-		call.PageToken(string(*pageToken))
+		params.Set("pageToken", string(*pageToken))
 	}
 
-	response, err := call.Do()
+	response, err := doCachedCall(ctx, r, "playlistItems.list", params, call.IfNoneMatch, call.Context(ctx).Do)
 	if err != nil {
 		return nil, 0, nil, fmt.Errorf("failed to list playlist items: %w", err)
 	}
@@ -188,84 +306,381 @@ func (r *youtubeRepository) ListVideoIDsByPlaylist(
 	return videoIDs, response.PageInfo.TotalResults, nextPageToken, nil
 }
 
-func pageTokenFromString(token string) *repository.YouTubePageToken {
-	if token == "" {
-		return nil
-	}
+// ListPlaylistItems is like ListVideoIDsByPlaylist, but also returns the position of each video
+// and when it was added, which ListVideoIDsByPlaylist drops.
+func (r *youtubeRepository) ListPlaylistItems(
+	ctx context.Context,
+	playlistID model.YouTubePlaylistID,
+	pageToken *repository.YouTubePageToken,
+) ([]*model.YouTubePlaylistItem, int64, *repository.YouTubePageToken, error) {
+	parts := []string{"contentDetails", "snippet"}
 
-	t := repository.YouTubePageToken(token)
+	call := r.service.PlaylistItems.List(parts)
 
-	return &t
-}
+	params := url.Values{
+		"part":       parts,
+		"playlistId": {string(playlistID)},
+		"maxResults": {strconv.Itoa(repository.YouTubeMaxResults)},
+	}
 
-func videoFromYouTubeVideo(video *youtube.Video) (*model.YouTubeVideo, error) {
-	var duration time.Duration
-	if video.ContentDetails.Duration != "P0D" {
-		dur, err := time.ParseDuration(strings.ToLower(strings.TrimPrefix(video.ContentDetails.Duration, "PT")))
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse duration: %w", err)
-		}
-		duration = dur
+	if pageToken != nil {
+		params.Set("pageToken", string(*pageToken))
+	}
+
+	response, err := doCachedCall(ctx, r, "playlistItems.list", params, call.IfNoneMatch, call.Context(ctx).Do)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to list playlist items: %w", err)
 	}
 
-	var thumbnails model.YouTubeVideoThumbnails
-	{
-		defaultURL, err := thumbnailURLFromYouTubeThumbnail(video.Snippet.Thumbnails.Default)
+	items := make([]*model.YouTubePlaylistItem, 0, len(response.Items))
+	for _, item := range response.Items {
+		addedAt, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse default thumbnail URL: %w", err)
+			return nil, 0, nil, fmt.Errorf("failed to parse published at: %w", err)
 		}
 
-		mediumURL, err := thumbnailURLFromYouTubeThumbnail(video.Snippet.Thumbnails.Medium)
+		videoPublishedAt, err := optionalTimeFromString(item.ContentDetails.VideoPublishedAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse medium thumbnail URL: %w", err)
+			return nil, 0, nil, fmt.Errorf("failed to parse video published at: %w", err)
 		}
 
-		highURL, err := thumbnailURLFromYouTubeThumbnail(video.Snippet.Thumbnails.High)
+		items = append(items, &model.YouTubePlaylistItem{
+			VideoID:          model.YouTubeVideoID(item.ContentDetails.VideoId),
+			Position:         item.Snippet.Position,
+			AddedAt:          addedAt,
+			VideoPublishedAt: videoPublishedAt,
+		})
+	}
+
+	nextPageToken := pageTokenFromString(response.NextPageToken)
+
+	return items, response.PageInfo.TotalResults, nextPageToken, nil
+}
+
+// ListCommentThreads lists the comment threads of the video, newest first.
+func (r *youtubeRepository) ListCommentThreads(
+	ctx context.Context,
+	videoID model.YouTubeVideoID,
+	pageToken *repository.YouTubePageToken,
+) ([]*model.YouTubeCommentThread, int64, *repository.YouTubePageToken, error) {
+	parts := []string{"snippet", "replies"}
+
+	call := r.service.CommentThreads.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"videoId":    {string(videoID)},
+		"order":      {"time"},
+		"textFormat": {"plainText"},
+		"maxResults": {strconv.Itoa(commentThreadsMaxResults)},
+	}
+
+	if pageToken != nil {
+		params.Set("pageToken", string(*pageToken))
+	}
+
+	response, err := doCachedCall(ctx, r, "commentThreads.list", params, call.IfNoneMatch, call.Context(ctx).Do)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to list comment threads: %w", err)
+	}
+
+	threads := make([]*model.YouTubeCommentThread, 0, len(response.Items))
+	for _, item := range response.Items {
+		topLevelComment, err := commentFromYouTubeComment(item.Snippet.TopLevelComment)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse high thumbnail URL: %w", err)
+			return nil, 0, nil, fmt.Errorf("failed to convert top level comment: %w", err)
+		}
+		topLevelComment.VideoID = videoID
+		topLevelComment.ReplyCount = item.Snippet.TotalReplyCount
+
+		var replies []*model.YouTubeComment
+		if item.Replies != nil {
+			replies = make([]*model.YouTubeComment, 0, len(item.Replies.Comments))
+			for _, reply := range item.Replies.Comments {
+				comment, err := commentFromYouTubeComment(reply)
+				if err != nil {
+					return nil, 0, nil, fmt.Errorf("failed to convert reply: %w", err)
+				}
+				comment.VideoID = videoID
+				replies = append(replies, comment)
+			}
 		}
 
-		standardURL, err := thumbnailURLFromYouTubeThumbnail(video.Snippet.Thumbnails.Standard)
+		threads = append(threads, &model.YouTubeCommentThread{
+			TopLevelComment: topLevelComment,
+			Replies:         replies,
+		})
+	}
+
+	nextPageToken := pageTokenFromString(response.NextPageToken)
+
+	return threads, response.PageInfo.TotalResults, nextPageToken, nil
+}
+
+// ListCommentReplies lists the replies to the comment.
+// The video ID of the replies may be empty since the API does not always return it.
+func (r *youtubeRepository) ListCommentReplies(
+	ctx context.Context,
+	parentID model.YouTubeCommentID,
+	pageToken *repository.YouTubePageToken,
+) ([]*model.YouTubeComment, int64, *repository.YouTubePageToken, error) {
+	parts := []string{"snippet"}
+
+	call := r.service.Comments.List(parts)
+
+	params := url.Values{
+		"part":       parts,
+		"parentId":   {string(parentID)},
+		"textFormat": {"plainText"},
+		"maxResults": {strconv.Itoa(commentsMaxResults)},
+	}
+
+	if pageToken != nil {
+		params.Set("pageToken", string(*pageToken))
+	}
+
+	response, err := doCachedCall(ctx, r, "comments.list", params, call.IfNoneMatch, call.Context(ctx).Do)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to list comment replies: %w", err)
+	}
+
+	replies := make([]*model.YouTubeComment, 0, len(response.Items))
+	for _, item := range response.Items {
+		reply, err := commentFromYouTubeComment(item)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse standard thumbnail URL: %w", err)
+			return nil, 0, nil, fmt.Errorf("failed to convert reply: %w", err)
 		}
+		replies = append(replies, reply)
+	}
+
+	nextPageToken := pageTokenFromString(response.NextPageToken)
+
+	return replies, response.PageInfo.TotalResults, nextPageToken, nil
+}
+
+// ListCaptionTracks lists the caption tracks of the video. The request is authorized
+// with the token source if one is given, since YouTube may refuse it with the API key only.
+func (r *youtubeRepository) ListCaptionTracks(
+	ctx context.Context,
+	videoID model.YouTubeVideoID,
+) ([]*model.YouTubeCaptionTrack, error) {
+	parts := []string{"snippet"}
+
+	service := r.service
+	if r.oauthService != nil {
+		service = r.oauthService
+	}
 
-		maxresURL, err := thumbnailURLFromYouTubeThumbnail(video.Snippet.Thumbnails.Maxres)
+	call := service.Captions.List(parts, string(videoID))
+
+	response, err := doCall(ctx, r, "captions.list", call.Context(ctx).Do)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list caption tracks: %w", err)
+	}
+
+	tracks := make([]*model.YouTubeCaptionTrack, 0, len(response.Items))
+	for _, item := range response.Items {
+		updatedAt, err := time.Parse(time.RFC3339, item.Snippet.LastUpdated)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse maxres thumbnail URL: %w", err)
+			return nil, fmt.Errorf("failed to parse last updated: %w", err)
 		}
 
-		thumbnails = model.YouTubeVideoThumbnails{
-			Default:  defaultURL,
-			Medium:   mediumURL,
-			High:     highURL,
-			Standard: standardURL,
-			Maxres:   maxresURL,
-		}
+		tracks = append(tracks, &model.YouTubeCaptionTrack{
+			ID:        model.YouTubeCaptionID(item.Id),
+			VideoID:   videoID,
+			Language:  item.Snippet.Language,
+			Name:      item.Snippet.Name,
+			TrackKind: model.YouTubeCaptionTrackKind(strings.ToLower(item.Snippet.TrackKind)),
+			UpdatedAt: updatedAt,
+		})
 	}
 
-	var liveStreamingDetails *model.YouTubeVideoLiveStreamingDetails
-	if video.LiveStreamingDetails != nil && video.LiveStreamingDetails.ActualEndTime != "" {
-		actualStartTime, err := time.Parse(time.RFC3339, video.LiveStreamingDetails.ActualStartTime)
+	return tracks, nil
+}
+
+// DownloadCaption downloads the caption track and parses it into segments.
+// It needs a token source allowed to download the track, which is usually the video owner's.
+func (r *youtubeRepository) DownloadCaption(
+	ctx context.Context,
+	captionID model.YouTubeCaptionID,
+) ([]*model.YouTubeCaptionSegment, error) {
+	if r.oauthService == nil {
+		return nil, fmt.Errorf("%w: downloading captions needs a token source", repository.ErrUnauthorized)
+	}
+
+	call := r.oauthService.Captions.Download(string(captionID)).Tfmt(captionFormat)
+
+	response, err := doCall(ctx, r, "captions.download", call.Context(ctx).Download)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download caption: %w", err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caption: %w", err)
+	}
+
+	segments, err := parseCaption(captionFormat, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse caption: %w", err)
+	}
+
+	return segments, nil
+}
+
+// ListLiveChatMessages lists the messages posted to the live chat since pageToken,
+// or the recent ones if pageToken is nil. It also returns how long to wait before
+// asking for the next page, and a nil next page token once the chat has gone offline.
+// Message types that are not archived, such as deletions and bans, are left out.
+func (r *youtubeRepository) ListLiveChatMessages(
+	ctx context.Context,
+	liveChatID model.YouTubeLiveChatID,
+	pageToken *repository.YouTubePageToken,
+) ([]*model.YouTubeLiveChatMessage, *repository.YouTubePageToken, time.Duration, error) {
+	parts := []string{"snippet", "authorDetails"}
+
+	call := r.service.LiveChatMessages.List(string(liveChatID), parts).
+		MaxResults(liveChatMessagesMaxResults)
+
+	if pageToken != nil {
+		call.PageToken(string(*pageToken))
+	}
+
+	// The chat changes with every poll, so the response is never cached.
+	response, err := doCall(ctx, r, "liveChatMessages.list", call.Context(ctx).Do)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to list live chat messages: %w", err)
+	}
+
+	messages := make([]*model.YouTubeLiveChatMessage, 0, len(response.Items))
+	for _, item := range response.Items {
+		message, err := liveChatMessageFromYouTubeLiveChatMessage(item)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse actual start time: %w", err)
+			// A malformed message should not cost the rest of the page
+			log.Printf("youtube: skipped live chat message %s: %v", item.Id, err)
+			continue
+		}
+		if message != nil {
+			messages = append(messages, message)
 		}
+	}
+
+	pollingInterval := time.Duration(response.PollingIntervalMillis) * time.Millisecond
+
+	if response.OfflineAt != "" {
+		return messages, nil, pollingInterval, nil
+	}
+
+	return messages, pageTokenFromString(response.NextPageToken), pollingInterval, nil
+}
+
+// reserveQuota reserves the quota for a request to the endpoint, if quota is tracked.
+func (r *youtubeRepository) reserveQuota(ctx context.Context, endpoint string) error {
+	if r.quotaMeter == nil {
+		return nil
+	}
 
-		actualEndTime, err := time.Parse(time.RFC3339, video.LiveStreamingDetails.ActualEndTime)
+	return r.quotaMeter.Reserve(ctx, youtubeQuotaCosts[endpoint])
+}
+
+func pageTokenFromString(token string) *repository.YouTubePageToken {
+	if token == "" {
+		return nil
+	}
+
+	t := repository.YouTubePageToken(token)
+
+	return &t
+}
+
+func channelFromYouTubeChannel(channel *youtube.Channel) (*model.YouTubeChannel, error) {
+	publishedAt, err := time.Parse(time.RFC3339, channel.Snippet.PublishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse published at: %w", err)
+	}
+
+	// The high thumbnail is the largest avatar (800x800).
+	var avatarURL *url.URL
+	if channel.Snippet.Thumbnails != nil {
+		avatarURL, err = thumbnailURLFromYouTubeThumbnail(channel.Snippet.Thumbnails.High)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse actual end time: %w", err)
+			return nil, fmt.Errorf("failed to parse avatar URL: %w", err)
 		}
+	}
 
-		scheduledStartTime, err := time.Parse(time.RFC3339, video.LiveStreamingDetails.ScheduledStartTime)
+	var bannerURL *url.URL
+	if channel.BrandingSettings != nil && channel.BrandingSettings.Image != nil && channel.BrandingSettings.Image.BannerExternalUrl != "" {
+		bannerURL, err = url.Parse(channel.BrandingSettings.Image.BannerExternalUrl)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse scheduled start time: %w", err)
+			return nil, fmt.Errorf("failed to parse banner URL: %w", err)
 		}
+	}
 
-		liveStreamingDetails = &model.YouTubeVideoLiveStreamingDetails{
-			ActualStartTime: actualStartTime,
-			ActualEndTime:   actualEndTime,
-			ScheduledStart:  scheduledStartTime,
+	var statistics *model.YouTubeChannelStatistics
+	if channel.Statistics != nil {
+		statistics = &model.YouTubeChannelStatistics{
+			ViewCount:  int64(channel.Statistics.ViewCount),
+			VideoCount: int64(channel.Statistics.VideoCount),
 		}
+		if !channel.Statistics.HiddenSubscriberCount {
+			subscriberCount := int64(channel.Statistics.SubscriberCount)
+			statistics.SubscriberCount = &subscriberCount
+		}
+	}
+
+	return &model.YouTubeChannel{
+		YouTubeChannelIdentity: model.YouTubeChannelIdentity{
+			ID:     model.YouTubeChannelID(channel.Id),
+			Handle: model.YouTubeChannelHandle(channel.Snippet.CustomUrl),
+		},
+		UploadsPlaylistID: model.YouTubePlaylistID(channel.ContentDetails.RelatedPlaylists.Uploads),
+		Title:             channel.Snippet.Title,
+		Description:       channel.Snippet.Description,
+		Country:           channel.Snippet.Country,
+		AvatarURL:         avatarURL,
+		BannerURL:         bannerURL,
+		PublishedAt:       publishedAt,
+		Statistics:        statistics,
+	}, nil
+}
+
+func playlistFromYouTubePlaylist(playlist *youtube.Playlist) (*model.YouTubePlaylist, error) {
+	thumbnails, err := thumbnailsFromYouTubeThumbnailDetails(playlist.Snippet.Thumbnails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse thumbnails: %w", err)
+	}
+
+	publishedAt, err := time.Parse(time.RFC3339, playlist.Snippet.PublishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse published at: %w", err)
+	}
+
+	return &model.YouTubePlaylist{
+		ID:            model.YouTubePlaylistID(playlist.Id),
+		Title:         playlist.Snippet.Title,
+		Description:   playlist.Snippet.Description,
+		Thumbnails:    *thumbnails,
+		ItemCount:     playlist.ContentDetails.ItemCount,
+		PrivacyStatus: model.YouTubePrivacyStatus(playlist.Status.PrivacyStatus),
+		PublishedAt:   publishedAt,
+	}, nil
+}
+
+func videoFromYouTubeVideo(video *youtube.Video) (*model.YouTubeVideo, error) {
+	duration, err := model.ParseISO8601Duration(video.ContentDetails.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse duration: %w", err)
+	}
+
+	thumbnails, err := thumbnailsFromYouTubeThumbnailDetails(video.Snippet.Thumbnails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse thumbnails: %w", err)
+	}
+
+	liveStreamingDetails, err := liveStreamingDetailsFromYouTubeVideo(video)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse live streaming details: %w", err)
 	}
 
 	publishedAt, err := time.Parse(time.RFC3339, video.Snippet.PublishedAt)
@@ -273,14 +688,318 @@ func videoFromYouTubeVideo(video *youtube.Video) (*model.YouTubeVideo, error) {
 		return nil, fmt.Errorf("failed to parse published at: %w", err)
 	}
 
-	return &model.YouTubeVideo{
+	var statistics *model.YouTubeVideoStatistics
+	if video.Statistics != nil {
+		statistics = &model.YouTubeVideoStatistics{
+			ViewCount:    int64(video.Statistics.ViewCount),
+			LikeCount:    int64(video.Statistics.LikeCount),
+			CommentCount: int64(video.Statistics.CommentCount),
+		}
+	}
+
+	var regionRestriction *model.YouTubeRegionRestriction
+	if r := video.ContentDetails.RegionRestriction; r != nil && (r.Allowed != nil || r.Blocked != nil) {
+		regionRestriction = &model.YouTubeRegionRestriction{
+			Allowed: r.Allowed,
+			Blocked: r.Blocked,
+		}
+	}
+
+	v := &model.YouTubeVideo{
 		ID:                   model.YouTubeVideoID(video.Id),
 		Title:                video.Snippet.Title,
 		Description:          video.Snippet.Description,
 		Duration:             duration,
-		Thumbnails:           thumbnails,
+		Thumbnails:           *thumbnails,
+		BroadcastState:       broadcastStateFromYouTubeVideo(video, duration),
 		LiveStreamingDetails: liveStreamingDetails,
 		PublishedAt:          publishedAt,
+		Statistics:           statistics,
+		Tags:                 video.Snippet.Tags,
+		CategoryID:           video.Snippet.CategoryId,
+		DefaultAudioLanguage: video.Snippet.DefaultAudioLanguage,
+		PrivacyStatus:        model.YouTubePrivacyStatus(video.Status.PrivacyStatus),
+		MadeForKids:          video.Status.MadeForKids,
+		RegionRestriction:    regionRestriction,
+		HasCaption:           video.ContentDetails.Caption == "true",
+		Definition:           model.YouTubeVideoDefinition(video.ContentDetails.Definition),
+		Availability:         availabilityFromYouTubeVideo(video),
+	}
+
+	vertical := video.Player != nil && video.Player.EmbedHeight > video.Player.EmbedWidth
+	v.Class = model.ClassifyYouTubeVideo(v, vertical)
+
+	return v, nil
+}
+
+// availabilityFromYouTubeVideo tells the availability from the privacy status.
+// Members-only videos look public here; they can only be told apart by the members-only playlist.
+func availabilityFromYouTubeVideo(video *youtube.Video) model.YouTubeVideoAvailability {
+	switch video.Status.PrivacyStatus {
+	case "unlisted":
+		return model.YouTubeVideoAvailabilityUnlisted
+	case "private":
+		return model.YouTubeVideoAvailabilityPrivate
+	default:
+		return model.YouTubeVideoAvailabilityAvailable
+	}
+}
+
+func broadcastStateFromYouTubeVideo(video *youtube.Video, duration time.Duration) model.YouTubeBroadcastState {
+	switch video.Snippet.LiveBroadcastContent {
+	case "upcoming", "live":
+		// A premiere is a pre-recorded video, so its duration is known before it starts,
+		// while a live stream has no duration until it ends.
+		if duration > 0 {
+			return model.YouTubeBroadcastStatePremiere
+		}
+		if video.Snippet.LiveBroadcastContent == "upcoming" {
+			return model.YouTubeBroadcastStateUpcoming
+		}
+		return model.YouTubeBroadcastStateLive
+	}
+
+	if video.LiveStreamingDetails != nil {
+		return model.YouTubeBroadcastStateCompleted
+	}
+
+	return model.YouTubeBroadcastStateNone
+}
+
+func liveStreamingDetailsFromYouTubeVideo(video *youtube.Video) (*model.YouTubeVideoLiveStreamingDetails, error) {
+	if video.LiveStreamingDetails == nil {
+		return nil, nil
+	}
+
+	actualStartTime, err := optionalTimeFromString(video.LiveStreamingDetails.ActualStartTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse actual start time: %w", err)
+	}
+
+	actualEndTime, err := optionalTimeFromString(video.LiveStreamingDetails.ActualEndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse actual end time: %w", err)
+	}
+
+	// Streams started without a schedule have no scheduled start time, so fall back to the actual one.
+	var scheduledStartTime time.Time
+	switch {
+	case video.LiveStreamingDetails.ScheduledStartTime != "":
+		scheduledStartTime, err = time.Parse(time.RFC3339, video.LiveStreamingDetails.ScheduledStartTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse scheduled start time: %w", err)
+		}
+	case actualStartTime != nil:
+		scheduledStartTime = *actualStartTime
+	default:
+		return nil, fmt.Errorf("neither scheduled nor actual start time is set")
+	}
+
+	var activeLiveChatID *model.YouTubeLiveChatID
+	if video.LiveStreamingDetails.ActiveLiveChatId != "" {
+		id := model.YouTubeLiveChatID(video.LiveStreamingDetails.ActiveLiveChatId)
+		activeLiveChatID = &id
+	}
+
+	return &model.YouTubeVideoLiveStreamingDetails{
+		ActualStartTime:  actualStartTime,
+		ActualEndTime:    actualEndTime,
+		ScheduledStart:   scheduledStartTime,
+		ActiveLiveChatID: activeLiveChatID,
+	}, nil
+}
+
+func optionalTimeFromString(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func commentFromYouTubeComment(comment *youtube.Comment) (*model.YouTubeComment, error) {
+	publishedAt, err := time.Parse(time.RFC3339, comment.Snippet.PublishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse published at: %w", err)
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, comment.Snippet.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated at: %w", err)
+	}
+
+	var parentID *model.YouTubeCommentID
+	if comment.Snippet.ParentId != "" {
+		id := model.YouTubeCommentID(comment.Snippet.ParentId)
+		parentID = &id
+	}
+
+	var authorChannelID model.YouTubeChannelID
+	if comment.Snippet.AuthorChannelId != nil {
+		authorChannelID = model.YouTubeChannelID(comment.Snippet.AuthorChannelId.Value)
+	}
+
+	return &model.YouTubeComment{
+		ID:                model.YouTubeCommentID(comment.Id),
+		VideoID:           model.YouTubeVideoID(comment.Snippet.VideoId),
+		ParentID:          parentID,
+		AuthorDisplayName: comment.Snippet.AuthorDisplayName,
+		AuthorChannelID:   authorChannelID,
+		Text:              comment.Snippet.TextDisplay,
+		LikeCount:         comment.Snippet.LikeCount,
+		PublishedAt:       publishedAt,
+		UpdatedAt:         updatedAt,
+	}, nil
+}
+
+// liveChatMessageFromYouTubeLiveChatMessage converts the message, or returns nil if its type is not archived.
+// It returns an error if the message lacks the details of its type.
+func liveChatMessageFromYouTubeLiveChatMessage(item *youtube.LiveChatMessage) (*model.YouTubeLiveChatMessage, error) {
+	snippet := item.Snippet
+	if snippet == nil {
+		return nil, fmt.Errorf("missing snippet")
+	}
+
+	message := &model.YouTubeLiveChatMessage{
+		ID: model.YouTubeLiveChatMessageID(item.Id),
+	}
+
+	switch snippet.Type {
+	case "textMessageEvent":
+		message.Type = model.YouTubeLiveChatMessageTypeText
+		if snippet.TextMessageDetails != nil {
+			message.Text = snippet.TextMessageDetails.MessageText
+		}
+	case "superChatEvent":
+		details := snippet.SuperChatDetails
+		if details == nil {
+			return nil, fmt.Errorf("missing super chat details")
+		}
+		message.Type = model.YouTubeLiveChatMessageTypeSuperChat
+		message.Text = details.UserComment
+		message.SuperChat = &model.YouTubeSuperChat{
+			AmountMicros:  int64(details.AmountMicros),
+			Currency:      details.Currency,
+			AmountDisplay: details.AmountDisplayString,
+			Tier:          details.Tier,
+		}
+	case "superStickerEvent":
+		details := snippet.SuperStickerDetails
+		if details == nil {
+			return nil, fmt.Errorf("missing super sticker details")
+		}
+		message.Type = model.YouTubeLiveChatMessageTypeSuperSticker
+		message.SuperChat = &model.YouTubeSuperChat{
+			AmountMicros:  int64(details.AmountMicros),
+			Currency:      details.Currency,
+			AmountDisplay: details.AmountDisplayString,
+			Tier:          details.Tier,
+		}
+	case "newSponsorEvent":
+		details := snippet.NewSponsorDetails
+		if details == nil {
+			return nil, fmt.Errorf("missing new sponsor details")
+		}
+		message.Type = model.YouTubeLiveChatMessageTypeNewMember
+		message.Membership = &model.YouTubeMembershipEvent{
+			LevelName: details.MemberLevelName,
+		}
+	case "memberMilestoneChatEvent":
+		details := snippet.MemberMilestoneChatDetails
+		if details == nil {
+			return nil, fmt.Errorf("missing member milestone chat details")
+		}
+		message.Type = model.YouTubeLiveChatMessageTypeMemberMilestone
+		message.Text = details.UserComment
+		message.Membership = &model.YouTubeMembershipEvent{
+			LevelName: details.MemberLevelName,
+			Months:    details.MemberMonth,
+		}
+	case "membershipGiftingEvent":
+		details := snippet.MembershipGiftingDetails
+		if details == nil {
+			return nil, fmt.Errorf("missing membership gifting details")
+		}
+		message.Type = model.YouTubeLiveChatMessageTypeMembershipGifting
+		message.Membership = &model.YouTubeMembershipEvent{
+			LevelName: details.GiftMembershipsLevelName,
+			GiftCount: details.GiftMembershipsCount,
+		}
+	case "giftMembershipReceivedEvent":
+		details := snippet.GiftMembershipReceivedDetails
+		if details == nil {
+			return nil, fmt.Errorf("missing gift membership received details")
+		}
+		message.Type = model.YouTubeLiveChatMessageTypeGiftMembershipReceived
+		message.Membership = &model.YouTubeMembershipEvent{
+			LevelName: details.MemberLevelName,
+		}
+	default:
+		return nil, nil
+	}
+
+	publishedAt, err := time.Parse(time.RFC3339, snippet.PublishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse published at: %w", err)
+	}
+	message.PublishedAt = publishedAt
+
+	if item.AuthorDetails != nil {
+		message.Author = model.YouTubeLiveChatAuthor{
+			ChannelID:   model.YouTubeChannelID(item.AuthorDetails.ChannelId),
+			DisplayName: item.AuthorDetails.DisplayName,
+			IsOwner:     item.AuthorDetails.IsChatOwner,
+			IsModerator: item.AuthorDetails.IsChatModerator,
+			IsMember:    item.AuthorDetails.IsChatSponsor,
+		}
+	} else {
+		message.Author.ChannelID = model.YouTubeChannelID(snippet.AuthorChannelId)
+	}
+
+	return message, nil
+}
+
+func thumbnailsFromYouTubeThumbnailDetails(details *youtube.ThumbnailDetails) (*model.YouTubeThumbnails, error) {
+	if details == nil {
+		return &model.YouTubeThumbnails{}, nil
+	}
+
+	defaultURL, err := thumbnailURLFromYouTubeThumbnail(details.Default)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default thumbnail URL: %w", err)
+	}
+
+	mediumURL, err := thumbnailURLFromYouTubeThumbnail(details.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse medium thumbnail URL: %w", err)
+	}
+
+	highURL, err := thumbnailURLFromYouTubeThumbnail(details.High)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse high thumbnail URL: %w", err)
+	}
+
+	standardURL, err := thumbnailURLFromYouTubeThumbnail(details.Standard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse standard thumbnail URL: %w", err)
+	}
+
+	maxresURL, err := thumbnailURLFromYouTubeThumbnail(details.Maxres)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse maxres thumbnail URL: %w", err)
+	}
+
+	return &model.YouTubeThumbnails{
+		Default:  defaultURL,
+		Medium:   mediumURL,
+		High:     highURL,
+		Standard: standardURL,
+		Maxres:   maxresURL,
 	}, nil
 }
 
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+)
+
+// YouTubeMaxResults is the maximum number of items the YouTube Data API returns in a page,
+// which is also the maximum number of IDs a single list request accepts.
+const YouTubeMaxResults = 50
+
+// ListVideosInBatches fetches any number of videos by splitting videoIDs into chunks of YouTubeMaxResults
+// and fetching up to concurrency chunks at the same time.
+// The videos are returned in the order of videoIDs, together with the IDs the API did not return,
+// such as private or deleted videos. Duplicated IDs are fetched and returned only once.
+func ListVideosInBatches(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	videoIDs []model.YouTubeVideoID,
+	concurrency int,
+) ([]*model.YouTubeVideo, []model.YouTubeVideoID, error) {
+	if concurrency < 1 {
+		return nil, nil, fmt.Errorf("concurrency must be greater than 0")
+	}
+
+	ids := make([]model.YouTubeVideoID, 0, len(videoIDs))
+	seen := make(map[model.YouTubeVideoID]struct{}, len(videoIDs))
+	for _, id := range videoIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu      sync.Mutex
+		fetched = make(map[model.YouTubeVideoID]*model.YouTubeVideo, len(ids))
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+	)
+
+	for start := 0; start < len(ids); start += YouTubeMaxResults {
+		chunk := ids[start:min(start+YouTubeMaxResults, len(ids))]
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			videos, err := Collect(AllVideos(ctx, youtubeRepo, chunk))
+			if err != nil {
+				cancel(err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, video := range videos {
+				fetched[video.ID] = video
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	videos := make([]*model.YouTubeVideo, 0, len(fetched))
+	missingIDs := make([]model.YouTubeVideoID, 0)
+	for _, id := range ids {
+		if video, ok := fetched[id]; ok {
+			videos = append(videos, video)
+		} else {
+			missingIDs = append(missingIDs, id)
+		}
+	}
+
+	return videos, missingIDs, nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "context"
+
+// YouTubeResponseCache stores responses of the YouTube Data API together with their ETags,
+// so that a response that has not changed since the last request need not be downloaded again.
+type YouTubeResponseCache interface {
+	// Get returns the cached response for the request identified by key, or nil if there is none.
+	Get(ctx context.Context, key string) (*YouTubeCachedResponse, error)
+	// Put caches the response for the request identified by key, replacing any previous one.
+	Put(ctx context.Context, key string, response *YouTubeCachedResponse) error
+}
+
+// YouTubeAPIResponseCacheStore persists cached responses, for a YouTubeResponseCache that outlives the process.
+type YouTubeAPIResponseCacheStore interface {
+	// GetAPIResponseCache returns the response cached under key. It returns ErrNotFound if there is none.
+	GetAPIResponseCache(ctx context.Context, key string) (*YouTubeCachedResponse, error)
+	// PutAPIResponseCache caches the response under key, replacing any previous one.
+	PutAPIResponseCache(ctx context.Context, key string, response *YouTubeCachedResponse) error
+}
+
+type YouTubeCachedResponse struct {
+	ETag string
+	Body []byte // the response encoded as JSON
+}
@@ -0,0 +1,33 @@
+package repository
+
+import "errors"
+
+// Errors returned by the repositories, wrapping the underlying API or database error.
+// Callers should check them with errors.Is.
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrForbidden is returned when the credentials are not allowed to access the resource.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrUnauthorized is returned when the call needs OAuth credentials that are missing or not valid.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrLiveChatEnded is returned when the live chat has ended and no longer takes messages.
+	ErrLiveChatEnded = errors.New("live chat ended")
+
+	// ErrInvalidSignature is returned when a pushed notification is not signed with the shared secret.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrQuotaExceeded is returned when the daily quota of the YouTube Data API is used up.
+	// Retrying does not help until the quota is reset.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// ErrQuotaBudgetExceeded is returned instead of calling the YouTube Data API when the call
+	// would exceed the daily budget configured for the YouTubeQuotaMeter.
+	ErrQuotaBudgetExceeded = errors.New("quota budget exceeded")
+
+	// ErrRateLimited is returned when requests are sent too fast. Retrying later may succeed.
+	ErrRateLimited = errors.New("rate limited")
+)
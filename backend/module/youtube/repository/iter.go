@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"iter"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+)
+
+// AllPlaylists iterates over every playlist of the channel, fetching the next page as needed.
+func AllPlaylists(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	channelID model.YouTubeChannelID,
+) iter.Seq2[*model.YouTubePlaylist, error] {
+	return paginate(func(pageToken *YouTubePageToken) ([]*model.YouTubePlaylist, *YouTubePageToken, error) {
+		playlists, _, nextPageToken, err := youtubeRepo.ListPlaylists(ctx, channelID, pageToken)
+		return playlists, nextPageToken, err
+	})
+}
+
+// AllVideos iterates over the videos with the given IDs, fetching the next page as needed.
+// Like ListVideos, it accepts at most YouTubeMaxResults IDs; use ListVideosInBatches for more.
+func AllVideos(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	videoIDs []model.YouTubeVideoID,
+) iter.Seq2[*model.YouTubeVideo, error] {
+	return paginate(func(pageToken *YouTubePageToken) ([]*model.YouTubeVideo, *YouTubePageToken, error) {
+		videos, _, nextPageToken, err := youtubeRepo.ListVideos(ctx, videoIDs, pageToken)
+		return videos, nextPageToken, err
+	})
+}
+
+// AllVideoIDsByPlaylist iterates over the IDs of every video in the playlist, fetching the next page as needed.
+func AllVideoIDsByPlaylist(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	playlistID model.YouTubePlaylistID,
+) iter.Seq2[model.YouTubeVideoID, error] {
+	return paginate(func(pageToken *YouTubePageToken) ([]model.YouTubeVideoID, *YouTubePageToken, error) {
+		videoIDs, _, nextPageToken, err := youtubeRepo.ListVideoIDsByPlaylist(ctx, playlistID, pageToken)
+		return videoIDs, nextPageToken, err
+	})
+}
+
+// AllPlaylistItems iterates over every item of the playlist, fetching the next page as needed.
+func AllPlaylistItems(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	playlistID model.YouTubePlaylistID,
+) iter.Seq2[*model.YouTubePlaylistItem, error] {
+	return paginate(func(pageToken *YouTubePageToken) ([]*model.YouTubePlaylistItem, *YouTubePageToken, error) {
+		items, _, nextPageToken, err := youtubeRepo.ListPlaylistItems(ctx, playlistID, pageToken)
+		return items, nextPageToken, err
+	})
+}
+
+// AllCommentThreads iterates over the comment threads of the video, newest first, fetching the next page as needed.
+func AllCommentThreads(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	videoID model.YouTubeVideoID,
+) iter.Seq2[*model.YouTubeCommentThread, error] {
+	return paginate(func(pageToken *YouTubePageToken) ([]*model.YouTubeCommentThread, *YouTubePageToken, error) {
+		threads, _, nextPageToken, err := youtubeRepo.ListCommentThreads(ctx, videoID, pageToken)
+		return threads, nextPageToken, err
+	})
+}
+
+// AllCommentReplies iterates over every reply to the comment, fetching the next page as needed.
+func AllCommentReplies(
+	ctx context.Context,
+	youtubeRepo YouTubeRepository,
+	parentID model.YouTubeCommentID,
+) iter.Seq2[*model.YouTubeComment, error] {
+	return paginate(func(pageToken *YouTubePageToken) ([]*model.YouTubeComment, *YouTubePageToken, error) {
+		replies, _, nextPageToken, err := youtubeRepo.ListCommentReplies(ctx, parentID, pageToken)
+		return replies, nextPageToken, err
+	})
+}
+
+// Collect gathers every item of seq into a slice, stopping at the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	items := make([]T, 0)
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// paginate turns a paged list call into an iterator over its items.
+// A page is fetched only when the consumer has taken every item of the previous one,
+// so breaking out of the loop stops fetching. An error is yielded once and ends the iteration.
+func paginate[T any](
+	fetch func(pageToken *YouTubePageToken) ([]T, *YouTubePageToken, error),
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var pageToken *YouTubePageToken
+		for {
+			items, nextPageToken, err := fetch(pageToken)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if nextPageToken == nil {
+				return
+			}
+
+			pageToken = nextPageToken
+		}
+	}
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"net/url"
+	"time"
 
 	"github.com/tocoteron/omigoto/backend/module/youtube/model"
 )
@@ -9,6 +11,7 @@ import (
 type YouTubeRepository interface {
 	// Channel operations
 	GetChannel(ctx context.Context, channelID model.YouTubeChannelID) (*model.YouTubeChannel, error)
+	GetChannelByHandle(ctx context.Context, handle model.YouTubeChannelHandle) (*model.YouTubeChannel, error)
 
 	// Playlist operations
 	GetPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID) (*model.YouTubePlaylist, error)
@@ -17,32 +20,177 @@ type YouTubeRepository interface {
 	// Video operations
 	ListVideos(ctx context.Context, videoIDs []model.YouTubeVideoID, pageToken *YouTubePageToken) ([]*model.YouTubeVideo, int64, *YouTubePageToken, error)
 	ListVideoIDsByPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID, pageToken *YouTubePageToken) ([]model.YouTubeVideoID, int64, *YouTubePageToken, error)
+	ListPlaylistItems(ctx context.Context, playlistID model.YouTubePlaylistID, pageToken *YouTubePageToken) ([]*model.YouTubePlaylistItem, int64, *YouTubePageToken, error)
+
+	// Comment operations
+	ListCommentThreads(ctx context.Context, videoID model.YouTubeVideoID, pageToken *YouTubePageToken) ([]*model.YouTubeCommentThread, int64, *YouTubePageToken, error)
+	ListCommentReplies(ctx context.Context, parentID model.YouTubeCommentID, pageToken *YouTubePageToken) ([]*model.YouTubeComment, int64, *YouTubePageToken, error)
+
+	// Caption operations
+	ListCaptionTracks(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeCaptionTrack, error)
+	DownloadCaption(ctx context.Context, captionID model.YouTubeCaptionID) ([]*model.YouTubeCaptionSegment, error)
+
+	// Live chat operations
+	ListLiveChatMessages(ctx context.Context, liveChatID model.YouTubeLiveChatID, pageToken *YouTubePageToken) ([]*model.YouTubeLiveChatMessage, *YouTubePageToken, time.Duration, error)
 }
 
 type YouTubeDBRepository interface {
+	// Transaction operations
+	RunInTx(ctx context.Context, fn func(repo YouTubeDBRepository) error) error
+
 	// Channel operations
 	CreateChannel(ctx context.Context, channel *model.YouTubeChannel) error
+	UpsertChannel(ctx context.Context, channel *model.YouTubeChannel) (UpsertResult, error)
 	GetChannel(ctx context.Context, channelID model.YouTubeChannelID) (*model.YouTubeChannel, error)
 	GetChannelByHandle(ctx context.Context, handle model.YouTubeChannelHandle) (*model.YouTubeChannel, error)
 
+	// Channel snapshot operations
+	CreateChannelSnapshot(ctx context.Context, channelID model.YouTubeChannelID, snapshot *model.YouTubeChannelSnapshot) error
+	GetLatestChannelSnapshot(ctx context.Context, channelID model.YouTubeChannelID) (*model.YouTubeChannelSnapshot, error)
+	ListChannelSnapshots(ctx context.Context, channelID model.YouTubeChannelID, since time.Time, until time.Time) ([]*model.YouTubeChannelSnapshot, error)
+
 	// Playlist operations
 	CreatePlaylist(ctx context.Context, channelID model.YouTubeChannelID, playlist *model.YouTubePlaylist) error
+	UpsertPlaylist(ctx context.Context, channelID model.YouTubeChannelID, playlist *model.YouTubePlaylist) (UpsertResult, error)
 	GetPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID) (*model.YouTubePlaylist, error)
 	ListPlaylists(ctx context.Context, playlistIDs []model.YouTubePlaylistID) ([]*model.YouTubePlaylist, error)
 	ListPlaylistIDsByChannel(ctx context.Context, channelID model.YouTubeChannelID) ([]model.YouTubePlaylistID, error)
 
 	// Video operations
 	CreateVideo(ctx context.Context, video *model.YouTubeVideo) error
+	UpsertVideo(ctx context.Context, video *model.YouTubeVideo) (UpsertResult, error)
 	GetVideo(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeVideo, error)
-	ListVideos(ctx context.Context, videoIDs []model.YouTubeVideoID) ([]*model.YouTubeVideo, error)
+	ListVideos(ctx context.Context, videoIDs []model.YouTubeVideoID, filter YouTubeVideoFilter) ([]*model.YouTubeVideo, error)
+	ListVideoIDsByChannel(ctx context.Context, channelID model.YouTubeChannelID) ([]model.YouTubeVideoID, error)
+
+	// Video thumbnail archive operations
+	SaveVideoThumbnailArchive(ctx context.Context, videoID model.YouTubeVideoID, archive *model.YouTubeThumbnailArchive) (bool, error)
+	ListVideoThumbnailArchives(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeThumbnailArchive, error)
+	SetVideoThumbnailMissing(ctx context.Context, videoID model.YouTubeVideoID, size model.YouTubeThumbnailSize, u *url.URL, missingAt time.Time) error
+	SetVideoThumbnailsChecked(ctx context.Context, videoID model.YouTubeVideoID, checkedAt time.Time) error
+	ListVideoIDsWithThumbnailsToArchive(ctx context.Context, checkedBefore time.Time) ([]model.YouTubeVideoID, error)
+
+	// Video availability operations
+	SetVideoAvailability(ctx context.Context, videoID model.YouTubeVideoID, availability model.YouTubeVideoAvailability, observedAt time.Time) (bool, error)
+	ListVideoAvailabilityChanges(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeVideoAvailabilityChange, error)
+
+	// Video statistics operations
+	CreateVideoStatistics(ctx context.Context, videoID model.YouTubeVideoID, snapshot *model.YouTubeVideoStatisticsSnapshot) error
+	GetLatestVideoStatistics(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeVideoStatisticsSnapshot, error)
+	ListVideoStatistics(ctx context.Context, videoID model.YouTubeVideoID, since time.Time, until time.Time) ([]*model.YouTubeVideoStatisticsSnapshot, error)
+	ListFastestGrowingVideos(ctx context.Context, since time.Time, until time.Time, maxResults int32) ([]*model.YouTubeVideoViewGain, error)
 
 	// Playlist-Video relationship operations
-	CreatePlaylistVideo(ctx context.Context, playlistID model.YouTubePlaylistID, videoID model.YouTubeVideoID) error
-	ListVideoIDsByPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID) ([]model.YouTubeVideoID, error)
+	CreatePlaylistVideo(ctx context.Context, playlistID model.YouTubePlaylistID, item *model.YouTubePlaylistItem) error
+	UpsertPlaylistVideo(ctx context.Context, playlistID model.YouTubePlaylistID, item *model.YouTubePlaylistItem) (UpsertResult, error)
+	RemovePlaylistVideos(ctx context.Context, playlistID model.YouTubePlaylistID, listedItems []*model.YouTubePlaylistItem, removedAt time.Time) (int64, error)
+	ListVideoIDsByPlaylist(ctx context.Context, playlistID model.YouTubePlaylistID, filter YouTubeVideoFilter) ([]model.YouTubeVideoID, error)
+	ListPlaylistMemberships(ctx context.Context, playlistID model.YouTubePlaylistID) ([]*model.YouTubePlaylistMembership, error)
 
 	// Live streaming details operations
 	CreateVideoLiveStreamingDetails(ctx context.Context, videoID model.YouTubeVideoID, details *model.YouTubeVideoLiveStreamingDetails) error
+	UpsertVideoLiveStreamingDetails(ctx context.Context, videoID model.YouTubeVideoID, details *model.YouTubeVideoLiveStreamingDetails) (UpsertResult, error)
 	GetVideoLiveStreamingDetails(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeVideoLiveStreamingDetails, error)
+	ListLiveVideoIDs(ctx context.Context, since time.Time, until time.Time) ([]model.YouTubeVideoID, error)
+
+	// Comment operations
+	UpsertComment(ctx context.Context, comment *model.YouTubeComment) (UpsertResult, error)
+	GetCommentSync(ctx context.Context, videoID model.YouTubeVideoID) (*model.YouTubeCommentSync, error)
+	SetCommentSync(ctx context.Context, videoID model.YouTubeVideoID, sync *model.YouTubeCommentSync) error
+	ListCommentsByVideo(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeComment, error)
+
+	// Caption operations
+	UpsertCaptionTrack(ctx context.Context, track *model.YouTubeCaptionTrack) (UpsertResult, error)
+	ListCaptionTracks(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeCaptionTrack, error)
+	ListStaleCaptionTracks(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeCaptionTrack, error)
+	ReplaceCaptionSegments(ctx context.Context, track *model.YouTubeCaptionTrack, segments []*model.YouTubeCaptionSegment) error
+	ListCaptionSegments(ctx context.Context, captionID model.YouTubeCaptionID) ([]*model.YouTubeCaptionSegment, error)
+
+	// Live chat operations
+	CreateLiveChatMessages(ctx context.Context, videoID model.YouTubeVideoID, messages []*model.YouTubeLiveChatMessage) (int64, error)
+	ListLiveChatMessages(ctx context.Context, videoID model.YouTubeVideoID) ([]*model.YouTubeLiveChatMessage, error)
+}
+
+// YouTubeFeedRepository reads the public Atom feeds of channels.
+type YouTubeFeedRepository interface {
+	// ListFeedEntries returns the entries in the feed of the channel, newest first.
+	// The feed only has the latest 15 videos or so.
+	ListFeedEntries(ctx context.Context, channelID model.YouTubeChannelID) ([]*model.YouTubeFeedEntry, error)
+}
+
+// YouTubeImageRepository downloads images hosted by YouTube, such as thumbnails.
+type YouTubeImageRepository interface {
+	// GetImage returns the image at u and its content type.
+	// It returns ErrNotFound if the image has been removed.
+	GetImage(ctx context.Context, u *url.URL) ([]byte, string, error)
+}
+
+// BlobStore saves blobs, such as archived images, under keys like "sha256/ab/abcdef...".
+type BlobStore interface {
+	// Put saves data under key, replacing the blob already there.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the blob under key. It returns ErrNotFound if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Exists reports whether there is a blob under key.
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// YouTubeWebSubHub subscribes to the changes of channel feeds through a WebSub (PubSubHubbub) hub,
+// which pushes them to a callback URL.
+type YouTubeWebSubHub interface {
+	// Subscribe asks the hub to push the changes of the channel feed to callbackURL for lease.
+	// The hub confirms the subscription later by calling back with a challenge.
+	Subscribe(ctx context.Context, channelID model.YouTubeChannelID, callbackURL string, lease time.Duration) error
+	// TopicChannelID returns the channel whose feed the topic URL is, or false if it is not a channel feed.
+	TopicChannelID(topic string) (model.YouTubeChannelID, bool)
+	// ParseNotification parses a pushed body after checking its signature header.
+	// It returns ErrInvalidSignature if the body is not signed with the shared secret.
+	ParseNotification(body []byte, signature string) (*model.YouTubeFeedNotification, error)
+}
+
+// YouTubeQuotaMeter keeps track of the YouTube Data API quota used per day.
+type YouTubeQuotaMeter interface {
+	// Reserve records that a call costing the given units is about to be made.
+	// It returns ErrQuotaBudgetExceeded, recording nothing, if the call would exceed the daily budget.
+	Reserve(ctx context.Context, units int64) error
+	// Usage returns the units used today.
+	Usage(ctx context.Context) (int64, error)
+}
+
+// YouTubeQuotaStore persists the YouTube Data API quota used per day, for YouTubeQuotaMeter.
+type YouTubeQuotaStore interface {
+	// ReserveAPIQuota adds units to the usage of the date and returns the new usage.
+	// It returns ErrQuotaBudgetExceeded, adding nothing, if the usage would exceed budget.
+	ReserveAPIQuota(ctx context.Context, date time.Time, units int64, budget int64) (int64, error)
+	// GetAPIQuotaUsage returns the usage of the date, which is 0 if nothing has been reserved.
+	GetAPIQuotaUsage(ctx context.Context, date time.Time) (int64, error)
 }
 
 type YouTubePageToken string
+
+// UpsertResult reports what an Upsert* method did to the stored row.
+type UpsertResult int
+
+const (
+	UpsertResultUnchanged UpsertResult = iota // the row already existed with the same values
+	UpsertResultInserted                      // the row did not exist and was inserted
+	UpsertResultUpdated                       // the row existed and at least one value was changed
+)
+
+func (r UpsertResult) String() string {
+	switch r {
+	case UpsertResultUnchanged:
+		return "unchanged"
+	case UpsertResultInserted:
+		return "inserted"
+	case UpsertResultUpdated:
+		return "updated"
+	default:
+		return "unknown"
+	}
+}
+
+// YouTubeVideoFilter narrows down the videos listed from the database. The zero value matches every video.
+type YouTubeVideoFilter struct {
+	Class model.YouTubeVideoClass // empty for every class
+}
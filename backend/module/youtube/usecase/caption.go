@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+// YouTubeCaptionSyncStats counts what a caption sync of a video changed in the database.
+type YouTubeCaptionSyncStats struct {
+	Tracks UpsertCounts
+
+	// Downloaded counts the tracks whose segments were downloaded and saved.
+	Downloaded int
+	// Skipped counts the tracks that the token source is not allowed to download.
+	Skipped int
+}
+
+// SyncVideoCaptions saves the caption tracks of the video and downloads the segments
+// of the tracks that are new or updated since their segments were last saved.
+//
+// Downloads are expensive (200 units each), so tracks whose segments are up to date
+// are never downloaded again. Without a token source the tracks are still saved,
+// and the error wraps repository.ErrUnauthorized.
+func (u *YouTubeSyncUsecase) SyncVideoCaptions(ctx context.Context, videoID model.YouTubeVideoID) (*YouTubeCaptionSyncStats, error) {
+	tracks, err := u.youtubeRepo.ListCaptionTracks(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list caption tracks: %w", err)
+	}
+
+	stats := &YouTubeCaptionSyncStats{}
+	err = u.youtubeDBRepo.RunInTx(ctx, func(repo repository.YouTubeDBRepository) error {
+		stats.Tracks = UpsertCounts{}
+		for _, track := range tracks {
+			result, err := repo.UpsertCaptionTrack(ctx, track)
+			if err != nil {
+				return fmt.Errorf("failed to upsert caption track %s: %w", track.ID, err)
+			}
+			stats.Tracks.Add(result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save caption tracks: %w", err)
+	}
+
+	staleTracks, err := u.youtubeDBRepo.ListStaleCaptionTracks(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale caption tracks: %w", err)
+	}
+
+	// Each track is saved as soon as it is downloaded, so that the quota spent on it
+	// is not wasted when a later download fails.
+	for _, track := range staleTracks {
+		segments, err := u.youtubeRepo.DownloadCaption(ctx, track.ID)
+		if errors.Is(err, repository.ErrForbidden) {
+			// Only the owner's tracks can be downloaded, and the owner may disallow it
+			stats.Skipped++
+			continue
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to download caption %s: %w", track.ID, err)
+		}
+
+		err = u.youtubeDBRepo.RunInTx(ctx, func(repo repository.YouTubeDBRepository) error {
+			return repo.ReplaceCaptionSegments(ctx, track, segments)
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to save caption segments of %s: %w", track.ID, err)
+		}
+		stats.Downloaded++
+	}
+
+	return stats, nil
+}
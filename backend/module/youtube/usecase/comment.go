@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+const (
+	// commentRefreshInterval is how often every comment thread of a video is listed again,
+	// to pick up new replies to and edits of threads older than the last sync.
+	commentRefreshInterval = 7 * 24 * time.Hour
+	// commentSyncMargin is how far before the last sync new threads are looked for,
+	// in case a thread shows up in the listing a little after it was published.
+	commentSyncMargin = 10 * time.Minute
+)
+
+// SyncVideoComments archives the comment threads of the video and their replies.
+//
+// Threads are listed newest first, and listing stops at the first thread published
+// before the last sync, so repeated syncs only spend quota on what is new.
+// Once every commentRefreshInterval, every thread is listed again instead,
+// which picks up new replies to and edits of older threads.
+// A video with comments disabled or no longer available is not an error; nothing is saved for it.
+func (u *YouTubeSyncUsecase) SyncVideoComments(ctx context.Context, videoID model.YouTubeVideoID) (UpsertCounts, error) {
+	lastSync, err := u.youtubeDBRepo.GetCommentSync(ctx, videoID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return UpsertCounts{}, fmt.Errorf("failed to get comment sync: %w", err)
+	}
+
+	syncedAt := time.Now()
+	sync := &model.YouTubeCommentSync{
+		SyncedAt:    syncedAt,
+		RefreshedAt: syncedAt,
+	}
+
+	var cutoff *time.Time
+	if lastSync != nil && syncedAt.Sub(lastSync.RefreshedAt) < commentRefreshInterval {
+		t := lastSync.SyncedAt.Add(-commentSyncMargin)
+		cutoff = &t
+		sync.RefreshedAt = lastSync.RefreshedAt
+	}
+
+	threads := make([]*model.YouTubeCommentThread, 0)
+	for thread, err := range repository.AllCommentThreads(ctx, u.youtubeRepo, videoID) {
+		if errors.Is(err, repository.ErrForbidden) || errors.Is(err, repository.ErrNotFound) {
+			// Comments are disabled on the video, or the video is gone
+			return UpsertCounts{}, nil
+		}
+		if err != nil {
+			return UpsertCounts{}, fmt.Errorf("failed to list comment threads: %w", err)
+		}
+
+		if cutoff != nil && thread.TopLevelComment.PublishedAt.Before(*cutoff) {
+			break
+		}
+
+		// Only a few replies come with the thread, so list the rest separately
+		if int64(len(thread.Replies)) < thread.TopLevelComment.ReplyCount {
+			replies, err := repository.Collect(repository.AllCommentReplies(ctx, u.youtubeRepo, thread.TopLevelComment.ID))
+			if err != nil {
+				return UpsertCounts{}, fmt.Errorf("failed to list replies to comment %s: %w", thread.TopLevelComment.ID, err)
+			}
+			for _, reply := range replies {
+				reply.VideoID = videoID
+			}
+			thread.Replies = replies
+		}
+
+		threads = append(threads, thread)
+	}
+
+	var counts UpsertCounts
+	err = u.youtubeDBRepo.RunInTx(ctx, func(repo repository.YouTubeDBRepository) error {
+		counts = UpsertCounts{}
+		for _, thread := range threads {
+			// Parents first, since replies reference them
+			result, err := repo.UpsertComment(ctx, thread.TopLevelComment)
+			if err != nil {
+				return fmt.Errorf("failed to upsert comment %s: %w", thread.TopLevelComment.ID, err)
+			}
+			counts.Add(result)
+
+			for _, reply := range thread.Replies {
+				result, err := repo.UpsertComment(ctx, reply)
+				if err != nil {
+					return fmt.Errorf("failed to upsert reply %s: %w", reply.ID, err)
+				}
+				counts.Add(result)
+			}
+		}
+
+		if err := repo.SetCommentSync(ctx, videoID, sync); err != nil {
+			return fmt.Errorf("failed to set comment sync: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return UpsertCounts{}, fmt.Errorf("failed to save comments: %w", err)
+	}
+
+	return counts, nil
+}
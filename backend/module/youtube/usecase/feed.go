@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+// YouTubeFeedUsecase picks up new videos of a channel from its Atom feed, spending quota
+// only on videos that are not in the database yet. It is cheap enough to run every few
+// minutes, between the full syncs of YouTubeSyncUsecase.
+type YouTubeFeedUsecase struct {
+	feedRepo      repository.YouTubeFeedRepository
+	youtubeRepo   repository.YouTubeRepository
+	youtubeDBRepo repository.YouTubeDBRepository
+}
+
+func NewYouTubeFeedUsecase(
+	feedRepo repository.YouTubeFeedRepository,
+	youtubeRepo repository.YouTubeRepository,
+	youtubeDBRepo repository.YouTubeDBRepository,
+) *YouTubeFeedUsecase {
+	return &YouTubeFeedUsecase{
+		feedRepo:      feedRepo,
+		youtubeRepo:   youtubeRepo,
+		youtubeDBRepo: youtubeDBRepo,
+	}
+}
+
+// SyncNewVideos fetches and upserts the videos in the feed of the channel that are not
+// in the database yet, and returns their IDs. When the feed has nothing new, no quota is used.
+//
+// Only the videos themselves are saved; their playlist memberships, statistics and so on
+// are filled in by the next full sync.
+func (u *YouTubeFeedUsecase) SyncNewVideos(ctx context.Context, channelID model.YouTubeChannelID) ([]model.YouTubeVideoID, UpsertCounts, error) {
+	entries, err := u.feedRepo.ListFeedEntries(ctx, channelID)
+	if err != nil {
+		return nil, UpsertCounts{}, fmt.Errorf("failed to list feed entries: %w", err)
+	}
+
+	videoIDs := make([]model.YouTubeVideoID, len(entries))
+	for i, entry := range entries {
+		videoIDs[i] = entry.VideoID
+	}
+
+	known, err := u.youtubeDBRepo.ListVideos(ctx, videoIDs, repository.YouTubeVideoFilter{})
+	if err != nil {
+		return nil, UpsertCounts{}, fmt.Errorf("failed to list known videos: %w", err)
+	}
+
+	seen := make(map[model.YouTubeVideoID]struct{}, len(known))
+	for _, video := range known {
+		seen[video.ID] = struct{}{}
+	}
+
+	unseenVideoIDs := make([]model.YouTubeVideoID, 0, len(videoIDs))
+	for _, id := range videoIDs {
+		if _, ok := seen[id]; !ok {
+			unseenVideoIDs = append(unseenVideoIDs, id)
+		}
+	}
+	if len(unseenVideoIDs) == 0 {
+		return nil, UpsertCounts{}, nil
+	}
+
+	counts, err := u.SyncVideos(ctx, unseenVideoIDs)
+	if err != nil {
+		return nil, UpsertCounts{}, err
+	}
+
+	return unseenVideoIDs, counts, nil
+}
+
+// SyncVideos fetches the videos from the API and upserts them into the database.
+// Videos the API does not return, such as private ones, are skipped.
+func (u *YouTubeFeedUsecase) SyncVideos(ctx context.Context, videoIDs []model.YouTubeVideoID) (UpsertCounts, error) {
+	videos, _, err := repository.ListVideosInBatches(ctx, u.youtubeRepo, videoIDs, listVideosConcurrency)
+	if err != nil {
+		return UpsertCounts{}, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	var counts UpsertCounts
+	err = u.youtubeDBRepo.RunInTx(ctx, func(repo repository.YouTubeDBRepository) error {
+		counts = UpsertCounts{}
+		for _, video := range videos {
+			result, err := repo.UpsertVideo(ctx, video)
+			if err != nil {
+				return fmt.Errorf("failed to upsert video %s: %w", video.ID, err)
+			}
+			counts.Add(result)
+		}
+		return nil
+	})
+	if err != nil {
+		return UpsertCounts{}, fmt.Errorf("failed to save videos: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+// ErrNoActiveLiveChat is returned when a broadcast has no live chat open to record,
+// because it has ended, has not opened its chat yet, or is not a broadcast at all.
+var ErrNoActiveLiveChat = errors.New("no active live chat")
+
+// liveBroadcastMaxAge is how long after its start a broadcast is assumed to have ended
+// if its end has not been recorded.
+const liveBroadcastMaxAge = 48 * time.Hour
+
+// YouTubeLiveChatUsecase records the live chat of broadcasts while they are live.
+type YouTubeLiveChatUsecase struct {
+	youtubeRepo   repository.YouTubeRepository
+	youtubeDBRepo repository.YouTubeDBRepository
+
+	// minPollingInterval is the shortest wait between polls, to keep busy chats from
+	// using up the quota; the interval the API asks for is honored when it is longer.
+	minPollingInterval time.Duration
+}
+
+func NewYouTubeLiveChatUsecase(
+	youtubeRepo repository.YouTubeRepository,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	minPollingInterval time.Duration,
+) *YouTubeLiveChatUsecase {
+	return &YouTubeLiveChatUsecase{
+		youtubeRepo:        youtubeRepo,
+		youtubeDBRepo:      youtubeDBRepo,
+		minPollingInterval: minPollingInterval,
+	}
+}
+
+// ListLiveVideoIDs returns the broadcasts that are live as far as the database knows,
+// and the upcoming ones scheduled to start within leadTime of now.
+// Broadcasts that started more than liveBroadcastMaxAge ago without their end being recorded,
+// such as ones made private while live, are assumed to have ended, and upcoming ones overdue
+// by as much are assumed to be abandoned.
+func (u *YouTubeLiveChatUsecase) ListLiveVideoIDs(ctx context.Context, now time.Time, leadTime time.Duration) ([]model.YouTubeVideoID, error) {
+	videoIDs, err := u.youtubeDBRepo.ListLiveVideoIDs(ctx, now.Add(-liveBroadcastMaxAge), now.Add(leadTime))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list live video IDs: %w", err)
+	}
+	return videoIDs, nil
+}
+
+// CaptureLiveChat polls the live chat of the broadcast and saves its messages
+// until the chat ends or ctx is canceled, and returns the number of messages saved.
+//
+// The video is fetched and upserted first, which also records its latest live streaming
+// details, so broadcasts that have ended drop out of ListLiveVideoIDs.
+// It returns ErrNoActiveLiveChat if the broadcast has no chat open.
+func (u *YouTubeLiveChatUsecase) CaptureLiveChat(ctx context.Context, videoID model.YouTubeVideoID) (int64, error) {
+	videos, _, _, err := u.youtubeRepo.ListVideos(ctx, []model.YouTubeVideoID{videoID}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get video: %w", err)
+	}
+	if len(videos) == 0 {
+		return 0, fmt.Errorf("video %s: %w", videoID, repository.ErrNotFound)
+	}
+	video := videos[0]
+
+	if _, err := u.youtubeDBRepo.UpsertVideo(ctx, video); err != nil {
+		return 0, fmt.Errorf("failed to upsert video: %w", err)
+	}
+
+	if video.LiveStreamingDetails == nil || video.LiveStreamingDetails.ActiveLiveChatID == nil {
+		return 0, ErrNoActiveLiveChat
+	}
+	liveChatID := *video.LiveStreamingDetails.ActiveLiveChatID
+
+	var saved int64
+	var pageToken *repository.YouTubePageToken
+	for {
+		messages, nextPageToken, pollingInterval, err := u.youtubeRepo.ListLiveChatMessages(ctx, liveChatID, pageToken)
+		if errors.Is(err, repository.ErrLiveChatEnded) || errors.Is(err, repository.ErrNotFound) {
+			return saved, nil
+		}
+		if err != nil {
+			return saved, fmt.Errorf("failed to list live chat messages: %w", err)
+		}
+
+		if len(messages) > 0 {
+			created, err := u.youtubeDBRepo.CreateLiveChatMessages(ctx, videoID, messages)
+			if err != nil {
+				return saved, fmt.Errorf("failed to save live chat messages: %w", err)
+			}
+			saved += created
+		}
+
+		if nextPageToken == nil {
+			// The chat has gone offline
+			return saved, nil
+		}
+		pageToken = nextPageToken
+
+		timer := time.NewTimer(max(pollingInterval, u.minPollingInterval))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return saved, context.Cause(ctx)
+		}
+	}
+}
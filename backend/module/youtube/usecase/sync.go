@@ -0,0 +1,421 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+// listVideosConcurrency is the number of ListVideos requests sent at the same time.
+const listVideosConcurrency = 4
+
+// YouTubeSyncUsecase copies a channel and everything reachable from it
+// (playlists, videos and their relationships) from the YouTube Data API into the database.
+type YouTubeSyncUsecase struct {
+	youtubeRepo   repository.YouTubeRepository
+	youtubeDBRepo repository.YouTubeDBRepository
+}
+
+// YouTubeSyncStats counts what a sync changed in the database.
+type YouTubeSyncStats struct {
+	Channels       UpsertCounts
+	Playlists      UpsertCounts
+	Videos         UpsertCounts
+	PlaylistVideos UpsertCounts
+
+	// PlaylistVideosRemoved counts the videos that have left a playlist since the previous sync.
+	PlaylistVideosRemoved int64
+
+	// ChannelProfileChanges lists the parts of the channel profile that changed since the previous sync.
+	ChannelProfileChanges []model.YouTubeChannelProfileField
+
+	// ViewMilestones lists the videos whose views passed a milestone since the previous sync.
+	ViewMilestones []ViewMilestone
+
+	// AvailabilityChanges lists the videos whose availability changed since the previous sync.
+	AvailabilityChanges []AvailabilityChange
+}
+
+// AvailabilityChange is a new availability observed for a video.
+type AvailabilityChange struct {
+	VideoID      model.YouTubeVideoID
+	Availability model.YouTubeVideoAvailability
+}
+
+// ViewMilestone is a milestone in model.YouTubeViewMilestones that a video has passed.
+type ViewMilestone struct {
+	VideoID   model.YouTubeVideoID
+	Milestone int64
+}
+
+// UpsertCounts tallies the results of Upsert* calls for one kind of row.
+type UpsertCounts struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+}
+
+func (c *UpsertCounts) Add(result repository.UpsertResult) {
+	switch result {
+	case repository.UpsertResultInserted:
+		c.Inserted++
+	case repository.UpsertResultUpdated:
+		c.Updated++
+	case repository.UpsertResultUnchanged:
+		c.Unchanged++
+	}
+}
+
+func (c UpsertCounts) String() string {
+	return fmt.Sprintf("inserted=%d updated=%d unchanged=%d", c.Inserted, c.Updated, c.Unchanged)
+}
+
+func NewYouTubeSyncUsecase(
+	youtubeRepo repository.YouTubeRepository,
+	youtubeDBRepo repository.YouTubeDBRepository,
+) *YouTubeSyncUsecase {
+	return &YouTubeSyncUsecase{
+		youtubeRepo:   youtubeRepo,
+		youtubeDBRepo: youtubeDBRepo,
+	}
+}
+
+// ResolveChannelID returns the ID of the channel with the given handle.
+// Channels that have been synced before are looked up in the database,
+// so only the first sync of a new channel spends quota on the lookup.
+func (u *YouTubeSyncUsecase) ResolveChannelID(ctx context.Context, handle model.YouTubeChannelHandle) (model.YouTubeChannelID, error) {
+	channel, err := u.youtubeDBRepo.GetChannelByHandle(ctx, handle)
+	if err == nil {
+		return channel.ID, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return "", fmt.Errorf("failed to get channel by handle from database: %w", err)
+	}
+
+	channel, err = u.youtubeRepo.GetChannelByHandle(ctx, handle)
+	if err != nil {
+		return "", fmt.Errorf("failed to get channel by handle: %w", err)
+	}
+
+	return channel.ID, nil
+}
+
+// SyncChannel fetches the channel, its playlists (including the uploads playlist)
+// and every video in them, then upserts all of them into the database.
+// Videos already in the database are fetched again to notice ones that became unavailable.
+// It is safe to run repeatedly; the returned stats tell what actually changed.
+func (u *YouTubeSyncUsecase) SyncChannel(ctx context.Context, channelID model.YouTubeChannelID) (*YouTubeSyncStats, error) {
+	channel, err := u.youtubeRepo.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	playlists, err := u.listAllPlaylists(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playlists: %w", err)
+	}
+
+	playlistItems := make(map[model.YouTubePlaylistID][]*model.YouTubePlaylistItem, len(playlists))
+	videoIDs := make([]model.YouTubeVideoID, 0)
+	seen := make(map[model.YouTubeVideoID]struct{})
+	for _, playlist := range playlists {
+		items, err := repository.Collect(repository.AllPlaylistItems(ctx, u.youtubeRepo, playlist.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list items of playlist %s: %w", playlist.ID, err)
+		}
+
+		playlistItems[playlist.ID] = items
+		for _, item := range items {
+			if _, ok := seen[item.VideoID]; ok {
+				continue
+			}
+			seen[item.VideoID] = struct{}{}
+			videoIDs = append(videoIDs, item.VideoID)
+		}
+	}
+
+	// Videos that have left every playlist, such as ones made unlisted, are only known to the database.
+	knownVideoIDs, err := u.youtubeDBRepo.ListVideoIDsByChannel(ctx, channel.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list known video IDs: %w", err)
+	}
+	for _, id := range knownVideoIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		videoIDs = append(videoIDs, id)
+	}
+
+	videos, missingVideoIDs, err := repository.ListVideosInBatches(ctx, u.youtubeRepo, videoIDs, listVideosConcurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	membersOnlyVideoIDs, err := u.listOptionalPlaylistVideoIDs(ctx, channel.ID.MembersOnlyPlaylistID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members-only video IDs: %w", err)
+	}
+
+	// The Shorts playlist is authoritative, while the adapter can only guess from the duration and aspect ratio.
+	shortsVideoIDs, err := u.listOptionalPlaylistVideoIDs(ctx, channel.ID.ShortsPlaylistID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Shorts video IDs: %w", err)
+	}
+	for _, video := range videos {
+		if _, ok := shortsVideoIDs[video.ID]; ok && video.Class == model.YouTubeVideoClassUpload {
+			video.Class = model.YouTubeVideoClassShort
+		}
+	}
+
+	fetched := &fetchedChannel{
+		channel:             channel,
+		playlists:           playlists,
+		playlistItems:       playlistItems,
+		videos:              videos,
+		missingVideoIDs:     missingVideoIDs,
+		membersOnlyVideoIDs: membersOnlyVideoIDs,
+	}
+	capturedAt := time.Now()
+
+	// The channel and its uploads playlist reference each other, so everything is saved
+	// in one transaction and rolled back together on failure.
+	var stats *YouTubeSyncStats
+	err = u.youtubeDBRepo.RunInTx(ctx, func(repo repository.YouTubeDBRepository) error {
+		s, err := saveChannel(ctx, repo, fetched, capturedAt)
+		if err != nil {
+			return err
+		}
+		stats = s
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save channel: %w", err)
+	}
+
+	return stats, nil
+}
+
+// fetchedChannel is everything fetched from the YouTube Data API during a sync.
+type fetchedChannel struct {
+	channel             *model.YouTubeChannel
+	playlists           []*model.YouTubePlaylist
+	playlistItems       map[model.YouTubePlaylistID][]*model.YouTubePlaylistItem
+	videos              []*model.YouTubeVideo
+	missingVideoIDs     []model.YouTubeVideoID // requested but not returned, because they are private or deleted
+	membersOnlyVideoIDs map[model.YouTubeVideoID]struct{}
+}
+
+func saveChannel(
+	ctx context.Context,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	fetched *fetchedChannel,
+	capturedAt time.Time,
+) (*YouTubeSyncStats, error) {
+	channel := fetched.channel
+	var stats YouTubeSyncStats
+
+	result, err := youtubeDBRepo.UpsertChannel(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save channel: %w", err)
+	}
+	stats.Channels.Add(result)
+
+	if snapshot := channel.Snapshot(capturedAt); snapshot != nil {
+		prev, err := youtubeDBRepo.GetLatestChannelSnapshot(ctx, channel.ID)
+		if err == nil {
+			stats.ChannelProfileChanges = snapshot.ProfileChanges(prev)
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get latest channel snapshot: %w", err)
+		}
+
+		err = youtubeDBRepo.CreateChannelSnapshot(ctx, channel.ID, snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save channel snapshot: %w", err)
+		}
+	}
+
+	for _, playlist := range fetched.playlists {
+		result, err := youtubeDBRepo.UpsertPlaylist(ctx, channel.ID, playlist)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save playlist %s: %w", playlist.ID, err)
+		}
+		stats.Playlists.Add(result)
+	}
+
+	// Videos that are private or deleted are listed in playlists but not returned by the API,
+	// so only the relationships to fetched videos can be saved.
+	saved := make(map[model.YouTubeVideoID]struct{}, len(fetched.videos))
+	for _, video := range fetched.videos {
+		result, err := youtubeDBRepo.UpsertVideo(ctx, video)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save video %s: %w", video.ID, err)
+		}
+		stats.Videos.Add(result)
+		saved[video.ID] = struct{}{}
+
+		if video.Statistics != nil {
+			milestone, err := saveVideoStatistics(ctx, youtubeDBRepo, video, capturedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to save statistics of video %s: %w", video.ID, err)
+			}
+			if milestone != nil {
+				stats.ViewMilestones = append(stats.ViewMilestones, *milestone)
+			}
+		}
+	}
+
+	for _, playlist := range fetched.playlists {
+		items := fetched.playlistItems[playlist.ID]
+		for _, item := range items {
+			if _, ok := saved[item.VideoID]; !ok {
+				continue
+			}
+
+			result, err := youtubeDBRepo.UpsertPlaylistVideo(ctx, playlist.ID, item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to save playlist video %s/%s: %w", playlist.ID, item.VideoID, err)
+			}
+			stats.PlaylistVideos.Add(result)
+		}
+
+		// Videos that are still listed but could not be saved keep their membership open.
+		removed, err := youtubeDBRepo.RemovePlaylistVideos(ctx, playlist.ID, items, capturedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove videos from playlist %s: %w", playlist.ID, err)
+		}
+		stats.PlaylistVideosRemoved += removed
+	}
+
+	changes, err := reconcileAvailability(ctx, youtubeDBRepo, fetched, capturedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile video availability: %w", err)
+	}
+	stats.AvailabilityChanges = changes
+
+	return &stats, nil
+}
+
+// reconcileAvailability compares the videos in the database with what the API returned,
+// and records the availability of each of them. Videos are never deleted from the database;
+// ones that disappeared are kept as tombstones marked private or deleted.
+func reconcileAvailability(
+	ctx context.Context,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	fetched *fetchedChannel,
+	observedAt time.Time,
+) ([]AvailabilityChange, error) {
+	availabilities := make(map[model.YouTubeVideoID]model.YouTubeVideoAvailability, len(fetched.videos)+len(fetched.missingVideoIDs))
+
+	for _, video := range fetched.videos {
+		availability := video.Availability
+		if _, ok := fetched.membersOnlyVideoIDs[video.ID]; ok {
+			availability = model.YouTubeVideoAvailabilityMembersOnly
+		}
+		availabilities[video.ID] = availability
+	}
+
+	// The API returns neither private nor deleted videos, but private ones stay listed in playlists.
+	// A private video that has been removed from every playlist is indistinguishable from a deleted one.
+	listed := make(map[model.YouTubeVideoID]struct{})
+	for _, items := range fetched.playlistItems {
+		for _, item := range items {
+			listed[item.VideoID] = struct{}{}
+		}
+	}
+	for _, id := range fetched.missingVideoIDs {
+		if _, ok := listed[id]; ok {
+			availabilities[id] = model.YouTubeVideoAvailabilityPrivate
+		} else {
+			availabilities[id] = model.YouTubeVideoAvailabilityDeleted
+		}
+	}
+
+	changes := make([]AvailabilityChange, 0)
+	for _, id := range slices.Sorted(maps.Keys(availabilities)) {
+		changed, err := youtubeDBRepo.SetVideoAvailability(ctx, id, availabilities[id], observedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set availability of video %s: %w", id, err)
+		}
+		if changed {
+			changes = append(changes, AvailabilityChange{VideoID: id, Availability: availabilities[id]})
+		}
+	}
+
+	return changes, nil
+}
+
+// saveVideoStatistics appends the statistics of the video to its series, and returns
+// the milestone it passed since the previous statistics, or nil if there is none.
+func saveVideoStatistics(
+	ctx context.Context,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	video *model.YouTubeVideo,
+	capturedAt time.Time,
+) (*ViewMilestone, error) {
+	var milestone *ViewMilestone
+	prev, err := youtubeDBRepo.GetLatestVideoStatistics(ctx, video.ID)
+	if err == nil {
+		if m, ok := model.CrossedViewMilestone(prev.Statistics.ViewCount, video.Statistics.ViewCount); ok {
+			milestone = &ViewMilestone{VideoID: video.ID, Milestone: m}
+		}
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get latest video statistics: %w", err)
+	}
+
+	err = youtubeDBRepo.CreateVideoStatistics(ctx, video.ID, &model.YouTubeVideoStatisticsSnapshot{
+		CapturedAt: capturedAt,
+		Statistics: *video.Statistics,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create video statistics: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// listOptionalPlaylistVideoIDs lists the videos in a playlist that YouTube keeps only for some channels,
+// such as the members-only playlist, which does not exist for channels without memberships.
+func (u *YouTubeSyncUsecase) listOptionalPlaylistVideoIDs(
+	ctx context.Context,
+	playlistID model.YouTubePlaylistID,
+) (map[model.YouTubeVideoID]struct{}, error) {
+	ids, err := repository.Collect(repository.AllVideoIDsByPlaylist(ctx, u.youtubeRepo, playlistID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return map[model.YouTubeVideoID]struct{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	videoIDs := make(map[model.YouTubeVideoID]struct{}, len(ids))
+	for _, id := range ids {
+		videoIDs[id] = struct{}{}
+	}
+
+	return videoIDs, nil
+}
+
+func (u *YouTubeSyncUsecase) listAllPlaylists(
+	ctx context.Context,
+	channel *model.YouTubeChannel,
+) ([]*model.YouTubePlaylist, error) {
+	// The uploads playlist is not returned by ListPlaylists, so it has to be fetched separately.
+	uploadsPlaylist, err := u.youtubeRepo.GetPlaylist(ctx, channel.UploadsPlaylistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uploads playlist: %w", err)
+	}
+
+	playlists, err := repository.Collect(repository.AllPlaylists(ctx, u.youtubeRepo, channel.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playlists: %w", err)
+	}
+
+	return append([]*model.YouTubePlaylist{uploadsPlaylist}, playlists...), nil
+}
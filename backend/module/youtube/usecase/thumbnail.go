@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+// YouTubeThumbnailArchiveUsecase downloads the thumbnails of videos and keeps every image
+// in the blob store by its SHA-256, so they survive YouTube replacing or removing them.
+type YouTubeThumbnailArchiveUsecase struct {
+	imageRepo       repository.YouTubeImageRepository
+	youtubeDBRepo   repository.YouTubeDBRepository
+	blobStore       repository.BlobStore
+	recheckInterval time.Duration
+}
+
+// NewYouTubeThumbnailArchiveUsecase creates the usecase. Archived thumbnails are downloaded again
+// once recheckInterval has passed, since YouTube can replace the image without changing its URL.
+func NewYouTubeThumbnailArchiveUsecase(
+	imageRepo repository.YouTubeImageRepository,
+	youtubeDBRepo repository.YouTubeDBRepository,
+	blobStore repository.BlobStore,
+	recheckInterval time.Duration,
+) *YouTubeThumbnailArchiveUsecase {
+	return &YouTubeThumbnailArchiveUsecase{
+		imageRepo:       imageRepo,
+		youtubeDBRepo:   youtubeDBRepo,
+		blobStore:       blobStore,
+		recheckInterval: recheckInterval,
+	}
+}
+
+// ListVideoIDsToArchive returns the videos that have a thumbnail not archived yet or due to be
+// checked again, newest first. Thumbnails found removed are left out until their URL changes.
+func (u *YouTubeThumbnailArchiveUsecase) ListVideoIDsToArchive(ctx context.Context) ([]model.YouTubeVideoID, error) {
+	videoIDs, err := u.youtubeDBRepo.ListVideoIDsWithThumbnailsToArchive(ctx, time.Now().Add(-u.recheckInterval))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video IDs: %w", err)
+	}
+	return videoIDs, nil
+}
+
+// ArchiveVideoThumbnails downloads the thumbnails of the video that are not archived yet,
+// saves them to the blob store and records their hashes, and returns the number of images
+// that had not been archived before. Thumbnails YouTube has already removed are recorded as such and skipped.
+// Once the recheck interval has passed, archived thumbnails are downloaded as well, and an image
+// whose hash differs from the recorded one is archived as a new image of its size.
+func (u *YouTubeThumbnailArchiveUsecase) ArchiveVideoThumbnails(ctx context.Context, videoID model.YouTubeVideoID) (int, error) {
+	video, err := u.youtubeDBRepo.GetVideo(ctx, videoID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	checkedAt := time.Now()
+	recheck := video.ThumbnailsCheckedAt == nil || video.ThumbnailsCheckedAt.Before(checkedAt.Add(-u.recheckInterval))
+
+	hashes := map[model.YouTubeThumbnailSize]string{
+		model.YouTubeThumbnailSizeDefault:  video.ThumbnailHashes.Default,
+		model.YouTubeThumbnailSizeMedium:   video.ThumbnailHashes.Medium,
+		model.YouTubeThumbnailSizeHigh:     video.ThumbnailHashes.High,
+		model.YouTubeThumbnailSizeStandard: video.ThumbnailHashes.Standard,
+		model.YouTubeThumbnailSizeMaxres:   video.ThumbnailHashes.Maxres,
+	}
+
+	archived := 0
+	for _, size := range model.YouTubeThumbnailSizes {
+		thumbnailURL := video.Thumbnails.URL(size)
+		if thumbnailURL == nil || (hashes[size] != "" && !recheck) {
+			continue
+		}
+
+		data, contentType, err := u.imageRepo.GetImage(ctx, thumbnailURL)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Recorded so that the image is not looked for again until the URL changes
+			log.Printf("thumbnail %s of video %s has been removed", size, videoID)
+			if err := u.youtubeDBRepo.SetVideoThumbnailMissing(ctx, videoID, size, thumbnailURL, time.Now()); err != nil {
+				return archived, fmt.Errorf("failed to record thumbnail %s as removed: %w", size, err)
+			}
+			continue
+		}
+		if err != nil {
+			return archived, fmt.Errorf("failed to get thumbnail %s: %w", size, err)
+		}
+
+		hash, err := u.putBlob(ctx, data, contentType)
+		if err != nil {
+			return archived, fmt.Errorf("failed to save thumbnail %s: %w", size, err)
+		}
+		if hash == hashes[size] {
+			continue
+		}
+		if hashes[size] != "" {
+			log.Printf("thumbnail %s of video %s has been replaced at the same URL", size, videoID)
+		}
+
+		var isNew bool
+		err = u.youtubeDBRepo.RunInTx(ctx, func(repo repository.YouTubeDBRepository) error {
+			var err error
+			isNew, err = repo.SaveVideoThumbnailArchive(ctx, videoID, &model.YouTubeThumbnailArchive{
+				Size:       size,
+				SHA256:     hash,
+				URL:        thumbnailURL,
+				ArchivedAt: time.Now(),
+			})
+			return err
+		})
+		if err != nil {
+			return archived, fmt.Errorf("failed to record thumbnail %s: %w", size, err)
+		}
+		if isNew {
+			archived++
+		}
+	}
+
+	if err := u.youtubeDBRepo.SetVideoThumbnailsChecked(ctx, videoID, checkedAt); err != nil {
+		return archived, fmt.Errorf("failed to record thumbnails as checked: %w", err)
+	}
+
+	return archived, nil
+}
+
+// putBlob saves data under its content address unless it is already there, and returns its hex SHA-256.
+func (u *YouTubeThumbnailArchiveUsecase) putBlob(ctx context.Context, data []byte, contentType string) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := thumbnailBlobKey(hash)
+
+	exists, err := u.blobStore.Exists(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to check blob: %w", err)
+	}
+	if exists {
+		return hash, nil
+	}
+
+	if err := u.blobStore.Put(ctx, key, data, contentType); err != nil {
+		return "", fmt.Errorf("failed to put blob: %w", err)
+	}
+
+	return hash, nil
+}
+
+// thumbnailBlobKey is the key of an image in the blob store, fanned out by the first byte of
+// its hash to keep directories small.
+func thumbnailBlobKey(hash string) string {
+	return "sha256/" + hash[:2] + "/" + hash
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tocoteron/omigoto/backend/module/youtube/model"
+	"github.com/tocoteron/omigoto/backend/module/youtube/repository"
+)
+
+const (
+	// webSubVerifyTimeout is how long to wait for the hub to verify a subscription before asking again.
+	webSubVerifyTimeout = 10 * time.Minute
+	// webSubRetryInterval is how long to wait before asking again after the hub refused a subscription.
+	webSubRetryInterval = 1 * time.Minute
+	// webSubMinRenewalWait is the shortest wait before renewing a verified subscription,
+	// however short the lease the hub granted.
+	webSubMinRenewalWait = 1 * time.Minute
+)
+
+// YouTubeWebSubUsecase keeps a channel subscribed to the WebSub hub and saves the videos it pushes,
+// so new uploads and scheduled broadcasts reach the database within seconds.
+type YouTubeWebSubUsecase struct {
+	hub         repository.YouTubeWebSubHub
+	feedUsecase *YouTubeFeedUsecase
+	channelID   model.YouTubeChannelID
+	callbackURL string
+	lease       time.Duration
+
+	mu             sync.Mutex
+	pending        bool          // whether a subscription has been asked for and not verified yet
+	leaseExpiresAt time.Time     // zero until the hub verifies the subscription
+	verified       chan struct{} // signaled whenever the hub verifies the subscription
+}
+
+func NewYouTubeWebSubUsecase(
+	hub repository.YouTubeWebSubHub,
+	feedUsecase *YouTubeFeedUsecase,
+	channelID model.YouTubeChannelID,
+	callbackURL string,
+	lease time.Duration,
+) *YouTubeWebSubUsecase {
+	return &YouTubeWebSubUsecase{
+		hub:         hub,
+		feedUsecase: feedUsecase,
+		channelID:   channelID,
+		callbackURL: callbackURL,
+		lease:       lease,
+		verified:    make(chan struct{}, 1),
+	}
+}
+
+// MaintainSubscription subscribes to the channel and renews the subscription before its lease
+// expires, until ctx is canceled. Subscriptions the hub does not verify in time are asked again.
+func (u *YouTubeWebSubUsecase) MaintainSubscription(ctx context.Context) error {
+	for {
+		// The hub may call back before Subscribe returns, so the subscription is pending from here
+		u.setPending(true)
+
+		wait := webSubVerifyTimeout
+		if err := u.hub.Subscribe(ctx, u.channelID, u.callbackURL, u.lease); err != nil {
+			log.Printf("websub: failed to subscribe to channel %s: %v", u.channelID, err)
+			u.setPending(false)
+			wait = webSubRetryInterval
+		}
+
+		if err := u.waitForRenewal(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// waitForRenewal waits until it is time to subscribe again: after timeout if the hub does not
+// verify the subscription by then, or when a tenth of the lease is left if it does.
+func (u *YouTubeWebSubUsecase) waitForRenewal(ctx context.Context, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case <-u.verified:
+			u.mu.Lock()
+			expiresAt := u.leaseExpiresAt
+			u.mu.Unlock()
+
+			log.Printf("websub: subscribed to channel %s until %s", u.channelID, expiresAt.Format(time.RFC3339))
+			timer.Reset(max(time.Until(expiresAt)*9/10, webSubMinRenewalWait))
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// VerifyIntent reports whether the subscription the hub is verifying is one this usecase asked for
+// and is still waiting on, in which case the hub's challenge must be echoed back.
+// It records the lease granted by the hub; a lease of 0, meaning the hub gave none, or one longer
+// than asked for is taken as the lease asked for.
+func (u *YouTubeWebSubUsecase) VerifyIntent(mode string, topic string, lease time.Duration) bool {
+	channelID, ok := u.hub.TopicChannelID(topic)
+	if !ok || channelID != u.channelID {
+		return false
+	}
+
+	// Unsubscriptions are never asked for, so they must come from someone else
+	if mode != "subscribe" {
+		return false
+	}
+
+	if lease <= 0 || lease > u.lease {
+		lease = u.lease
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// Verifications are accepted once per subscription asked for, so nobody else can
+	// extend the lease or make the subscription renewed over and over
+	if !u.pending {
+		return false
+	}
+	u.pending = false
+	u.leaseExpiresAt = time.Now().Add(lease)
+
+	select {
+	case u.verified <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// HandleNotification saves the videos in a notification pushed by the hub.
+// Notifications that are not correctly signed wrap repository.ErrInvalidSignature and change nothing.
+func (u *YouTubeWebSubUsecase) HandleNotification(ctx context.Context, body []byte, signature string) (UpsertCounts, error) {
+	notification, err := u.hub.ParseNotification(body, signature)
+	if err != nil {
+		return UpsertCounts{}, fmt.Errorf("failed to parse notification: %w", err)
+	}
+
+	videoIDs := make([]model.YouTubeVideoID, 0, len(notification.Entries))
+	for _, entry := range notification.Entries {
+		if entry.ChannelID != u.channelID {
+			continue
+		}
+		videoIDs = append(videoIDs, entry.VideoID)
+	}
+
+	for _, videoID := range notification.DeletedVideoIDs {
+		// The next full sync confirms it and records the new availability
+		log.Printf("websub: video %s was deleted or made private", videoID)
+	}
+
+	if len(videoIDs) == 0 {
+		return UpsertCounts{}, nil
+	}
+
+	counts, err := u.feedUsecase.SyncVideos(ctx, videoIDs)
+	if err != nil {
+		return UpsertCounts{}, fmt.Errorf("failed to sync pushed videos: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (u *YouTubeWebSubUsecase) setPending(pending bool) {
+	u.mu.Lock()
+	u.pending = pending
+	u.mu.Unlock()
+}
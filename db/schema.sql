@@ -4,13 +4,42 @@ CREATE TABLE youtube_channels (
     channel_id TEXT PRIMARY KEY,       -- UC1cnByKe24JjTv38tH_7BYw
     handle TEXT NOT NULL UNIQUE,       -- @izuho_omi
     uploads_playlist_id TEXT NOT NULL, -- UU1cnByKe24JjTv38tH_7BYw
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',  -- JP; empty if not set
+    avatar_url TEXT,
+    banner_url TEXT,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch',
     CONSTRAINT youtube_channels_uploads_playlist_id_fkey FOREIGN KEY (uploads_playlist_id) REFERENCES youtube_playlists (playlist_id) DEFERRABLE INITIALLY DEFERRED
 );
 
+-- Profile and statistics of a channel, captured on every sync to keep its history.
+CREATE TABLE youtube_channel_snapshots (
+    channel_id TEXT NOT NULL REFERENCES youtube_channels (channel_id),
+    captured_at TIMESTAMPTZ NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    avatar_url TEXT,
+    banner_url TEXT,
+    subscriber_count BIGINT, -- NULL if the channel hides it
+    view_count BIGINT NOT NULL,
+    video_count BIGINT NOT NULL,
+    PRIMARY KEY (channel_id, captured_at)
+);
+
 CREATE TABLE youtube_playlists (
     playlist_id TEXT PRIMARY KEY,
     channel_id TEXT NOT NULL,
     title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    thumbnail_default_url TEXT,  -- 120x90
+    thumbnail_medium_url TEXT,   -- 320x180
+    thumbnail_high_url TEXT,     -- 480x360
+    thumbnail_standard_url TEXT, -- 640x480
+    thumbnail_maxres_url TEXT,   -- 1280x720
+    item_count BIGINT NOT NULL DEFAULT 0,
+    privacy_status TEXT NOT NULL DEFAULT 'public', -- public, unlisted, private
+    published_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch',
     CONSTRAINT youtube_playlists_channel_id_fkey FOREIGN KEY (channel_id) REFERENCES youtube_channels (channel_id) DEFERRABLE INITIALLY DEFERRED
 );
 
@@ -24,18 +53,152 @@ CREATE TABLE youtube_videos (
     thumbnail_high_url TEXT,     -- 480x360
     thumbnail_standard_url TEXT, -- 640x480
     thumbnail_maxres_url TEXT,   -- 1280x720
-    published_at TIMESTAMPTZ NOT NULL
+    published_at TIMESTAMPTZ NOT NULL,
+    broadcast_state TEXT NOT NULL DEFAULT 'none', -- none, upcoming, live, completed, premiere
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    category_id TEXT NOT NULL DEFAULT '',            -- 20 (Gaming), 10 (Music), ...
+    default_audio_language TEXT NOT NULL DEFAULT '', -- ja; empty if not set
+    privacy_status TEXT NOT NULL DEFAULT 'public',   -- public, unlisted, private
+    made_for_kids BOOLEAN NOT NULL DEFAULT false,
+    region_restriction_allowed TEXT[],               -- NULL unless only available in these regions
+    region_restriction_blocked TEXT[],               -- NULL unless blocked in these regions
+    has_caption BOOLEAN NOT NULL DEFAULT false,
+    definition TEXT NOT NULL DEFAULT 'hd',           -- hd, sd
+    availability TEXT NOT NULL DEFAULT 'available',  -- available, unlisted, private, members_only, deleted
+    class TEXT NOT NULL DEFAULT 'upload',            -- short, upload, live_archive, premiere
+    thumbnail_default_sha256 TEXT,                   -- hex SHA-256 of the archived image at thumbnail_default_url; NULL until archived
+    thumbnail_medium_sha256 TEXT,
+    thumbnail_high_sha256 TEXT,
+    thumbnail_standard_sha256 TEXT,
+    thumbnail_maxres_sha256 TEXT,
+    thumbnail_default_missing_at TIMESTAMPTZ,        -- when the image at thumbnail_default_url was found removed; NULL unless so
+    thumbnail_medium_missing_at TIMESTAMPTZ,
+    thumbnail_high_missing_at TIMESTAMPTZ,
+    thumbnail_standard_missing_at TIMESTAMPTZ,
+    thumbnail_maxres_missing_at TIMESTAMPTZ,
+    thumbnails_checked_at TIMESTAMPTZ                -- when the images at the thumbnail URLs were last downloaded; NULL until then
+);
+
+-- Availability changes of videos, recorded when a new availability is first observed.
+-- Videos are never deleted, so that the archive remembers content taken down.
+CREATE TABLE youtube_video_availability_changes (
+    video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
+    observed_at TIMESTAMPTZ NOT NULL,
+    availability TEXT NOT NULL,
+    PRIMARY KEY (video_id, observed_at)
+);
+
+-- Every thumbnail image of videos archived to the blob store, keyed by its SHA-256,
+-- so images replaced or removed on YouTube can still be found.
+CREATE TABLE youtube_video_thumbnail_archives (
+    video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
+    size TEXT NOT NULL,   -- default, medium, high, standard, maxres
+    sha256 TEXT NOT NULL, -- hex; the blob is stored under sha256/<first 2 chars>/<sha256>
+    url TEXT NOT NULL,    -- where the image was downloaded from
+    archived_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (video_id, size, sha256)
 );
 
 CREATE TABLE youtube_video_live_streaming_details (
     video_id TEXT PRIMARY KEY REFERENCES youtube_videos (video_id),
-    actual_start_time TIMESTAMPTZ NOT NULL,
-    actual_end_time TIMESTAMPTZ NOT NULL,
+    actual_start_time TIMESTAMPTZ, -- NULL until the broadcast starts
+    actual_end_time TIMESTAMPTZ,   -- NULL until the broadcast ends
     scheduled_start_time TIMESTAMPTZ NOT NULL
 );
 
+-- Engagement of a video, captured on every sync to keep its history.
+CREATE TABLE youtube_video_statistics (
+    video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
+    captured_at TIMESTAMPTZ NOT NULL,
+    view_count BIGINT NOT NULL,
+    like_count BIGINT NOT NULL,    -- 0 if likes are hidden
+    comment_count BIGINT NOT NULL, -- 0 if comments are disabled
+    PRIMARY KEY (video_id, captured_at)
+);
+
+-- Membership of videos in playlists, as intervals from when a video was added until it was removed.
+-- A video that is removed and added again has one row for each time.
 CREATE TABLE youtube_playlist_videos (
     playlist_id TEXT NOT NULL REFERENCES youtube_playlists (playlist_id),
     video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
-    PRIMARY KEY (playlist_id, video_id)
+    added_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch',
+    removed_at TIMESTAMPTZ,              -- NULL while the video is in the playlist
+    position INTEGER NOT NULL DEFAULT 0, -- 0-based
+    video_published_at TIMESTAMPTZ,      -- NULL if the video is private
+    PRIMARY KEY (playlist_id, video_id, added_at)
+);
+
+-- Top-level comments and their replies.
+CREATE TABLE youtube_comments (
+    comment_id TEXT PRIMARY KEY,
+    video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
+    parent_id TEXT REFERENCES youtube_comments (comment_id), -- NULL for top-level comments
+    author_display_name TEXT NOT NULL,
+    author_channel_id TEXT NOT NULL,                        -- empty if unknown
+    text TEXT NOT NULL,
+    like_count BIGINT NOT NULL,
+    reply_count BIGINT NOT NULL,                            -- 0 for replies
+    published_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- How far the comments of each video have been synced.
+CREATE TABLE youtube_comment_syncs (
+    video_id TEXT PRIMARY KEY REFERENCES youtube_videos (video_id),
+    synced_at TIMESTAMPTZ NOT NULL,   -- when the comment threads were last listed
+    refreshed_at TIMESTAMPTZ NOT NULL -- when every comment thread was last listed, not just the new ones
+);
+
+-- Caption tracks of videos. A language may have both a standard and an automatically generated track.
+CREATE TABLE youtube_caption_tracks (
+    caption_id TEXT PRIMARY KEY,
+    video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
+    language TEXT NOT NULL,          -- BCP-47 language tag
+    name TEXT NOT NULL,
+    track_kind TEXT NOT NULL,        -- standard, asr or forced
+    updated_at TIMESTAMPTZ NOT NULL, -- when the track was last updated on YouTube
+    segments_updated_at TIMESTAMPTZ  -- updated_at of the track when its segments were saved; NULL until then
+);
+
+-- Timed text of caption tracks.
+CREATE TABLE youtube_caption_segments (
+    caption_id TEXT NOT NULL REFERENCES youtube_caption_tracks (caption_id),
+    seq INTEGER NOT NULL,            -- 0-based order in the track
+    start_offset INTERVAL NOT NULL,  -- from the start of the video
+    end_offset INTERVAL NOT NULL,
+    text TEXT NOT NULL,
+    PRIMARY KEY (caption_id, seq)
+);
+
+-- Live chat messages and events of broadcasts, recorded while they are live.
+CREATE TABLE youtube_live_chat_messages (
+    message_id TEXT PRIMARY KEY,
+    video_id TEXT NOT NULL REFERENCES youtube_videos (video_id),
+    message_type TEXT NOT NULL,       -- text, super_chat, super_sticker, new_member, member_milestone, membership_gifting or gift_membership_received
+    author_channel_id TEXT NOT NULL,
+    author_display_name TEXT NOT NULL,
+    author_is_owner BOOLEAN NOT NULL,
+    author_is_moderator BOOLEAN NOT NULL,
+    author_is_member BOOLEAN NOT NULL,
+    text TEXT NOT NULL,               -- empty if the author wrote nothing, as with most membership events
+    published_at TIMESTAMPTZ NOT NULL,
+    super_chat_amount_micros BIGINT,  -- NULL unless super_chat or super_sticker
+    super_chat_currency TEXT,         -- ISO 4217 currency code
+    super_chat_amount_display TEXT,   -- such as "¥1,000"
+    super_chat_tier BIGINT,
+    member_level_name TEXT,           -- NULL unless a membership event
+    member_months BIGINT,             -- NULL unless member_milestone
+    gift_count BIGINT                 -- NULL unless membership_gifting
+);
+
+CREATE TABLE youtube_api_quota_usage (
+    usage_date DATE PRIMARY KEY, -- in Pacific Time, where the daily quota of the YouTube Data API is reset
+    units BIGINT NOT NULL
+);
+
+CREATE TABLE youtube_api_response_cache (
+    cache_key TEXT PRIMARY KEY, -- endpoint and request parameters, such as videos.list?id=...&part=...
+    etag TEXT NOT NULL,
+    body BYTEA NOT NULL,        -- response encoded as JSON
+    updated_at TIMESTAMPTZ NOT NULL
 );